@@ -26,6 +26,10 @@ type Options struct {
 	// AllowedTools whitelist. nil/empty = all tools enabled (respecting ReadOnly)
 	AllowedTools []string
 
+	// LoadableApplications are applications that can be loaded by name with the app_load tool.
+	// The application behavior cannot be created from a name, so it must be provided here.
+	LoadableApplications map[gen.Atom]gen.ApplicationBehavior
+
 	// PoolSize is the number of worker processes in the tool execution pool (default: 5)
 	PoolSize int
 
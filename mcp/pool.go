@@ -36,8 +36,13 @@ func (p *MCPPool) Init(args ...any) (act.PoolOptions, error) {
 	registerDebugTools(registry)
 	registerSampleTools(registry)
 	registerLogLevelTools(registry)
+	registerSnapshotTools(registry)
 	if options.ReadOnly == false {
 		registerActionTools(registry)
+		registerCronActionTools(registry)
+		registerAppActionTools(registry, options.LoadableApplications)
+		registerEventActionTools(registry)
+		registerProcessActionTools(registry)
 	}
 
 	registry.filter(options.AllowedTools)
@@ -0,0 +1,305 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"ergo.services/ergo/act"
+	"ergo.services/ergo/gen"
+)
+
+const StoreName gen.Atom = "mcp_store"
+
+// maxSnapshots limits the number of snapshots kept by the store.
+// The oldest snapshot is evicted when the limit is reached.
+const maxSnapshots = 16
+
+type storeSnapshotPut struct {
+	Snapshot *nodeSnapshot
+}
+
+type storeSnapshotGet struct {
+	Name string
+}
+
+type storeSnapshotList struct{}
+
+type storeCronPut struct {
+	Name   gen.Atom
+	Spec   string
+	Action gen.CronAction
+}
+
+type storeCronGet struct {
+	Name gen.Atom
+}
+
+type storeCronDelete struct {
+	Name gen.Atom
+}
+
+// storeCronList returns the names of the jobs with a stored action ([]gen.Atom)
+type storeCronList struct{}
+
+type storeEventRegister struct {
+	Name    gen.Atom
+	Options gen.EventOptions
+	TTL     time.Duration
+}
+
+type storeEventPublish struct {
+	Name    gen.Atom
+	Message any
+}
+
+type storeEventUnregister struct {
+	Name gen.Atom
+}
+
+type storeEventList struct{}
+
+type messageStoreEventExpire struct {
+	Name      gen.Atom
+	ExpiresAt time.Time
+}
+
+// storeCronAction is the action of a cron job added via cron_job_add.
+type storeCronAction struct {
+	spec   string
+	action gen.CronAction
+}
+
+// storeEvent is an event registered via event_register and owned by the store.
+type storeEvent struct {
+	token      gen.Ref
+	expiresAt  time.Time
+	published  int
+	subscribed bool // has subscribers (reported if registered with notify)
+}
+
+// storeEventSummary describes an event owned by the store.
+type storeEventSummary struct {
+	Event      string    `json:"event"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Remaining  string    `json:"remaining"`
+	Published  int       `json:"published"`
+	Subscribed bool      `json:"subscribed"` // reported if registered with notify
+}
+
+// snapshotSummary is a short description of a stored snapshot.
+type snapshotSummary struct {
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+	Age         string    `json:"age"`
+	Processes   int       `json:"processes"`
+	Apps        int       `json:"applications"`
+	Events      int       `json:"events"`
+	Connections int       `json:"connections"`
+	Loggers     int       `json:"loggers"`
+	CronJobs    int       `json:"cron_jobs"`
+}
+
+func factoryMCPStore() gen.ProcessBehavior {
+	return &MCPStore{}
+}
+
+// MCPStore holds state that must outlive a single tool call (named snapshots,
+// actions of the cron jobs added via MCP, temporary events registered via MCP).
+// A cron job can be removed and re-added under the same name outside MCP, so
+// the kept action is valid only while the scheduler has the job with the same
+// spec; stale ones are dropped on access (Observer takes the actions from its
+// Options.CronActions instead, the application owns them there).
+// Events are owned by the store: only the owner can publish them.
+// Workers are stateless and short-lived per request, so they access it via Call.
+// No locks -- accessed only from actor callbacks.
+type MCPStore struct {
+	act.Actor
+	snapshots   map[string]*nodeSnapshot
+	cronActions map[gen.Atom]storeCronAction
+	events      map[gen.Atom]*storeEvent
+}
+
+func (s *MCPStore) Init(args ...any) error {
+	s.snapshots = make(map[string]*nodeSnapshot)
+	s.cronActions = make(map[gen.Atom]storeCronAction)
+	s.events = make(map[gen.Atom]*storeEvent)
+	return nil
+}
+
+func (s *MCPStore) HandleMessage(from gen.PID, message any) error {
+	switch m := message.(type) {
+	case messageStoreEventExpire:
+		event, ok := s.events[m.Name]
+		if ok == false || event.expiresAt.Equal(m.ExpiresAt) == false {
+			// unregistered already or registered again with a new deadline
+			return nil
+		}
+		s.UnregisterEvent(m.Name)
+		delete(s.events, m.Name)
+		s.Log().Info("temporary event %s expired", m.Name)
+
+	case gen.MessageEventStart:
+		// the event was registered with notify=true, the first subscriber came
+		if event, ok := s.events[m.Name]; ok {
+			event.subscribed = true
+			s.Log().Info("temporary event %s got the first subscriber", m.Name)
+		}
+
+	case gen.MessageEventStop:
+		// the last subscriber is gone
+		if event, ok := s.events[m.Name]; ok {
+			event.subscribed = false
+			s.Log().Info("temporary event %s has no subscribers", m.Name)
+		}
+
+	default:
+		s.Log().Warning("unknown message from %s: %#v", from, message)
+	}
+	return nil
+}
+
+func (s *MCPStore) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	switch r := request.(type) {
+	case storeSnapshotPut:
+		if _, exists := s.snapshots[r.Snapshot.Name]; exists == false && len(s.snapshots) >= maxSnapshots {
+			s.evictOldestSnapshot()
+		}
+		s.snapshots[r.Snapshot.Name] = r.Snapshot
+		return true, nil
+
+	case storeSnapshotGet:
+		snapshot, ok := s.snapshots[r.Name]
+		if ok == false {
+			return fmt.Errorf("snapshot %q not found. Use node_snapshot_list to list stored snapshots", r.Name), nil
+		}
+		return snapshot, nil
+
+	case storeSnapshotList:
+		list := make([]snapshotSummary, 0, len(s.snapshots))
+		for _, snapshot := range s.snapshots {
+			list = append(list, snapshot.summary())
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		})
+		return list, nil
+
+	case storeCronPut:
+		s.cronActions[r.Name] = storeCronAction{spec: r.Spec, action: r.Action}
+		return true, nil
+
+	case storeCronGet:
+		if s.validCronAction(r.Name) == false {
+			// nil result would mean "no reply", so report absence explicitly
+			return false, nil
+		}
+		return s.cronActions[r.Name].action, nil
+
+	case storeCronDelete:
+		delete(s.cronActions, r.Name)
+		return true, nil
+
+	case storeCronList:
+		names := make([]gen.Atom, 0, len(s.cronActions))
+		for name := range s.cronActions {
+			if s.validCronAction(name) {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+
+	case storeEventRegister:
+		if _, exists := s.events[r.Name]; exists {
+			return fmt.Errorf("event %s is already registered via MCP", r.Name), nil
+		}
+		token, err := s.RegisterEvent(r.Name, r.Options)
+		if err != nil {
+			return err, nil
+		}
+		event := &storeEvent{
+			token:     token,
+			expiresAt: time.Now().Add(r.TTL),
+		}
+		s.events[r.Name] = event
+		s.SendAfter(s.PID(), messageStoreEventExpire{Name: r.Name, ExpiresAt: event.expiresAt}, r.TTL)
+		return gen.Event{Name: r.Name, Node: s.Node().Name()}, nil
+
+	case storeEventPublish:
+		event, ok := s.events[r.Name]
+		if ok == false {
+			return fmt.Errorf("event %s is not registered via event_register, only events owned by MCP can be published", r.Name), nil
+		}
+		if err := s.SendEvent(r.Name, event.token, r.Message); err != nil {
+			return err, nil
+		}
+		event.published++
+		return true, nil
+
+	case storeEventUnregister:
+		if _, ok := s.events[r.Name]; ok == false {
+			return fmt.Errorf("event %s is not registered via event_register", r.Name), nil
+		}
+		delete(s.events, r.Name)
+		if err := s.UnregisterEvent(r.Name); err != nil {
+			return err, nil
+		}
+		return true, nil
+
+	case storeEventList:
+		list := make([]storeEventSummary, 0, len(s.events))
+		for name, event := range s.events {
+			list = append(list, storeEventSummary{
+				Event:      fmt.Sprintf("%s@%s", name, s.Node().Name()),
+				ExpiresAt:  event.expiresAt,
+				Remaining:  time.Until(event.expiresAt).Truncate(time.Second).String(),
+				Published:  event.published,
+				Subscribed: event.subscribed,
+			})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Event < list[j].Event
+		})
+		return list, nil
+	}
+
+	s.Log().Warning("unknown request from %s: %#v", from, request)
+	return nil, nil
+}
+
+func (s *MCPStore) evictOldestSnapshot() {
+	var oldest *nodeSnapshot
+	for _, snapshot := range s.snapshots {
+		if oldest == nil || snapshot.CreatedAt.Before(oldest.CreatedAt) {
+			oldest = snapshot
+		}
+	}
+	if oldest != nil {
+		delete(s.snapshots, oldest.Name)
+	}
+}
+
+// validCronAction reports whether the kept action still belongs to the
+// scheduled job, drops it otherwise.
+func (s *MCPStore) validCronAction(name gen.Atom) bool {
+	entry, exist := s.cronActions[name]
+	if exist == false {
+		return false
+	}
+	info, err := s.Node().Cron().JobInfo(name)
+	if err == nil && info.Spec == entry.spec {
+		return true
+	}
+	delete(s.cronActions, name)
+	return false
+}
+
+func (s *MCPStore) HandleInspect(from gen.PID, item ...string) map[string]string {
+	return map[string]string{
+		"snapshots":    fmt.Sprintf("%d/%d", len(s.snapshots), maxSnapshots),
+		"cron_actions": fmt.Sprintf("%d", len(s.cronActions)),
+		"events":       fmt.Sprintf("%d", len(s.events)),
+	}
+}
+
+func (s *MCPStore) Terminate(reason error) {}
@@ -22,6 +22,10 @@ func (s *mcpSup) Init(args ...any) (act.SupervisorSpec, error) {
 			Strategy: act.SupervisorStrategyPermanent,
 		},
 		Children: []act.SupervisorChildSpec{
+			{
+				Name:    StoreName,
+				Factory: factoryMCPStore,
+			},
 			{
 				Name:    PoolName,
 				Factory: factoryMCPPool,
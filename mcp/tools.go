@@ -22,13 +22,15 @@ type ToolDefinition struct {
 }
 
 type toolRegistry struct {
-	tools []ToolDefinition
-	index map[string]ToolHandler
+	tools   []ToolDefinition
+	index   map[string]ToolHandler
+	actions map[string]bool // tools that change the node state, audited on dispatch
 }
 
 func newToolRegistry() *toolRegistry {
 	return &toolRegistry{
-		index: make(map[string]ToolHandler),
+		index:   make(map[string]ToolHandler),
+		actions: make(map[string]bool),
 	}
 }
 
@@ -38,6 +40,13 @@ func (r *toolRegistry) register(def ToolDefinition) {
 	r.index[def.Name] = def.handler
 }
 
+// registerAction registers a tool that changes the node state.
+// Every call of such a tool is written to the audit log.
+func (r *toolRegistry) registerAction(def ToolDefinition) {
+	r.register(def)
+	r.actions[def.Name] = true
+}
+
 // injectNodeParam adds the "node" property to a tool's JSON schema.
 // This enables cluster proxy: when "node" is specified and differs from
 // the local node, the request is forwarded to the remote node's MCP pool.
@@ -96,6 +105,7 @@ func (r *toolRegistry) filter(allowed []string) {
 			filtered = append(filtered, def)
 		} else {
 			delete(r.index, def.Name)
+			delete(r.actions, def.Name)
 		}
 	}
 	r.tools = filtered
@@ -106,5 +116,18 @@ func (r *toolRegistry) dispatch(p gen.Process, name string, params json.RawMessa
 	if ok == false {
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
+	if r.actions[name] {
+		r.audit(p, name, params)
+	}
 	return h(p, params)
 }
+
+// audit writes the action tool invocation into the log of the process
+// handling it (worker or sampler), so every state change made via MCP is traceable.
+func (r *toolRegistry) audit(p gen.Process, name string, params json.RawMessage) {
+	args := "{}"
+	if len(params) > 0 {
+		args = string(params)
+	}
+	p.Log().Info("audit: action %s %s", name, args)
+}
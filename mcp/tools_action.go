@@ -42,7 +42,7 @@ func registerActionTools(r *toolRegistry) {
 		handler: toolMessageTypeInfo,
 	})
 
-	r.register(ToolDefinition{
+	r.registerAction(ToolDefinition{
 		Name:        "send_message",
 		Description: "Send an async message to a process. If type_name is specified, constructs a typed Go struct using reflection from the EDF type registry. Without type_name, sends the raw JSON value.",
 		InputSchema: json.RawMessage(`{
@@ -70,7 +70,7 @@ func registerActionTools(r *toolRegistry) {
 		handler: toolSendMessage,
 	})
 
-	r.register(ToolDefinition{
+	r.registerAction(ToolDefinition{
 		Name:        "call_process",
 		Description: "Make a synchronous request to a process and wait for response. If type_name is specified, constructs a typed request. Returns the process response.",
 		InputSchema: json.RawMessage(`{
@@ -102,7 +102,7 @@ func registerActionTools(r *toolRegistry) {
 		handler: toolCallProcess,
 	})
 
-	r.register(ToolDefinition{
+	r.registerAction(ToolDefinition{
 		Name:        "send_exit",
 		Description: "Send an exit signal to a process. The process receives a MessageExitPID in its Urgent mailbox and terminates by default (unless it traps exits).",
 		InputSchema: json.RawMessage(`{
@@ -122,7 +122,7 @@ func registerActionTools(r *toolRegistry) {
 		handler: toolSendExit,
 	})
 
-	r.register(ToolDefinition{
+	r.registerAction(ToolDefinition{
 		Name:        "process_kill",
 		Description: "Forcefully kill a process. The process transitions to Zombee state immediately. Use send_exit for graceful termination.",
 		InputSchema: json.RawMessage(`{
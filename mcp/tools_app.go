@@ -3,6 +3,7 @@ package mcp
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"ergo.services/ergo/gen"
@@ -191,3 +192,474 @@ func toolAppProcesses(w gen.Process, params json.RawMessage) (any, error) {
 	}
 	return textResult(text), nil
 }
+
+func registerAppActionTools(r *toolRegistry, loadable map[gen.Atom]gen.ApplicationBehavior) {
+	r.registerAction(ToolDefinition{
+		Name:        "app_start",
+		Description: "Starts a loaded application. Dependencies (ApplicationSpec.Depends) must be running.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Application name"
+				},
+				"mode": {
+					"type": "string",
+					"description": "Start mode. Default: mode from the application spec",
+					"enum": ["temporary", "transient", "permanent"]
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolAppStart,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "app_stop",
+		Description: "Stops a running application. Gracefully by default (waits for group processes to terminate), or forcefully (kills them). WARNING: stopping an application started in permanent mode shuts down the node.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Application name"
+				},
+				"force": {
+					"type": "boolean",
+					"description": "Kill group processes instead of graceful termination. Default: false"
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolAppStop,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "app_restart",
+		Description: "Restarts an application: stop and start again. Use dry_run=true first to see the plan: current state and mode, running applications depending on it (ApplicationSpec.Depends, transitively), and warnings. With restart_dependents=true, dependent applications are stopped before and started again after the restart.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Application name"
+				},
+				"mode": {
+					"type": "string",
+					"description": "Start mode after restart. Default: the mode the application is currently running in",
+					"enum": ["temporary", "transient", "permanent"]
+				},
+				"force": {
+					"type": "boolean",
+					"description": "Kill group processes instead of graceful termination. Default: false"
+				},
+				"restart_dependents": {
+					"type": "boolean",
+					"description": "Stop running dependent applications before and start them again after. Default: false (dependents keep running)"
+				},
+				"dry_run": {
+					"type": "boolean",
+					"description": "Only report what would be done, do not restart. Default: false"
+				},
+				"allow_node_shutdown": {
+					"type": "boolean",
+					"description": "Allow stopping applications running in permanent mode, which shuts down the node. Without it such a restart is refused. Default: false"
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolAppRestart,
+	})
+
+	// an action: loading changes the node state, so the call is audited
+	// (listing the loadable applications too, it's the same tool)
+	r.registerAction(ToolDefinition{
+		Name:        "app_load",
+		Description: "Loads an application by name. Only applications listed in mcp.Options.LoadableApplications can be loaded: the application behavior cannot be created from a name.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Application name (key in mcp.Options.LoadableApplications). Omit to list loadable applications"
+				}
+			}
+		}`),
+		handler: func(w gen.Process, params json.RawMessage) (any, error) {
+			return toolAppLoad(w, params, loadable)
+		},
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "app_unload",
+		Description: "Unloads a stopped application from the node.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Application name"
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolAppUnload,
+	})
+}
+
+func parseAppMode(s string) (gen.ApplicationMode, error) {
+	switch strings.ToLower(s) {
+	case "temporary":
+		return gen.ApplicationModeTemporary, nil
+	case "transient":
+		return gen.ApplicationModeTransient, nil
+	case "permanent":
+		return gen.ApplicationModePermanent, nil
+	}
+	return 0, fmt.Errorf("unknown application mode: %s", s)
+}
+
+// startApp starts the application in the given mode. Zero mode means the mode from its spec.
+func startApp(w gen.Process, name gen.Atom, mode gen.ApplicationMode) error {
+	options := gen.ApplicationOptions{}
+	switch mode {
+	case gen.ApplicationModeTemporary:
+		return w.Node().ApplicationStartTemporary(name, options)
+	case gen.ApplicationModeTransient:
+		return w.Node().ApplicationStartTransient(name, options)
+	case gen.ApplicationModePermanent:
+		return w.Node().ApplicationStartPermanent(name, options)
+	}
+	return w.Node().ApplicationStart(name, options)
+}
+
+func stopApp(w gen.Process, name gen.Atom, force bool) error {
+	if force {
+		return w.Node().ApplicationStopForce(name)
+	}
+	return w.Node().ApplicationStop(name)
+}
+
+type appStartParams struct {
+	Name string `json:"name"`
+	Mode string `json:"mode"`
+}
+
+func toolAppStart(w gen.Process, params json.RawMessage) (any, error) {
+	var p appStartParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	var mode gen.ApplicationMode
+	if p.Mode != "" {
+		m, err := parseAppMode(p.Mode)
+		if err != nil {
+			return nil, err
+		}
+		mode = m
+	}
+
+	if err := startApp(w, gen.Atom(p.Name), mode); err != nil {
+		return nil, fmt.Errorf("app_start: %w", err)
+	}
+	return textResult(fmt.Sprintf("application %s started", p.Name)), nil
+}
+
+type appStopParams struct {
+	Name  string `json:"name"`
+	Force bool   `json:"force"`
+}
+
+func toolAppStop(w gen.Process, params json.RawMessage) (any, error) {
+	var p appStopParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+	if gen.Atom(p.Name) == AppName {
+		return nil, fmt.Errorf("app_stop: %s cannot stop itself", AppName)
+	}
+
+	if err := stopApp(w, gen.Atom(p.Name), p.Force); err != nil {
+		return nil, fmt.Errorf("app_stop: %w", err)
+	}
+	return textResult(fmt.Sprintf("application %s stopped (force: %v)", p.Name, p.Force)), nil
+}
+
+type appRestartParams struct {
+	Name              string `json:"name"`
+	Mode              string `json:"mode"`
+	Force             bool   `json:"force"`
+	RestartDependents bool   `json:"restart_dependents"`
+	DryRun            bool   `json:"dry_run"`
+	AllowNodeShutdown bool   `json:"allow_node_shutdown"`
+}
+
+type appRestartDependent struct {
+	Name  gen.Atom `json:"name"`
+	State string   `json:"state"`
+	Mode  string   `json:"mode"`
+}
+
+type appRestartPlan struct {
+	Name              gen.Atom              `json:"name"`
+	State             string                `json:"state"`
+	CurrentMode       string                `json:"current_mode"`
+	StartMode         string                `json:"start_mode"`
+	Force             bool                  `json:"force"`
+	Dependents        []appRestartDependent `json:"dependents"`
+	RestartDependents bool                  `json:"restart_dependents"`
+	Warnings          []string              `json:"warnings,omitempty"`
+	DryRun            bool                  `json:"dry_run"`
+	Steps             []string              `json:"steps"`
+}
+
+// appDependents returns running applications depending on the given one
+// (directly or transitively), in the order they must be stopped.
+func appDependents(w gen.Process, name gen.Atom) []gen.ApplicationInfo {
+	infos := make(map[gen.Atom]gen.ApplicationInfo)
+	dependents := make(map[gen.Atom][]gen.Atom) // app -> apps depending on it
+	for _, app := range w.Node().Applications() {
+		info, err := w.Node().ApplicationInfo(app)
+		if err != nil {
+			continue
+		}
+		infos[app] = info
+		for _, dep := range info.Depends.Applications {
+			dependents[dep] = append(dependents[dep], app)
+		}
+	}
+
+	// DFS post-order on the dependents graph: an application comes after
+	// every application depending on it, so it is stopped after them
+	// (and started before them, in reverse order).
+	var order []gen.ApplicationInfo
+	visited := map[gen.Atom]bool{name: true}
+	var visit func(app gen.Atom)
+	visit = func(app gen.Atom) {
+		for _, dep := range dependents[app] {
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			visit(dep)
+			if infos[dep].State == gen.ApplicationStateRunning {
+				order = append(order, infos[dep])
+			}
+		}
+	}
+	visit(name)
+	return order
+}
+
+func toolAppRestart(w gen.Process, params json.RawMessage) (any, error) {
+	var p appRestartParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	name := gen.Atom(p.Name)
+	if name == AppName {
+		return nil, fmt.Errorf("app_restart: %s cannot restart itself", AppName)
+	}
+
+	info, err := w.Node().ApplicationInfo(name)
+	if err != nil {
+		return nil, fmt.Errorf("app_restart: %w", err)
+	}
+
+	mode := info.Mode
+	if p.Mode != "" {
+		mode, err = parseAppMode(p.Mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dependents := appDependents(w, name)
+
+	plan := appRestartPlan{
+		Name:              name,
+		State:             info.State.String(),
+		CurrentMode:       info.Mode.String(),
+		StartMode:         mode.String(),
+		Force:             p.Force,
+		Dependents:        make([]appRestartDependent, 0, len(dependents)),
+		RestartDependents: p.RestartDependents,
+		DryRun:            p.DryRun,
+	}
+
+	for _, dep := range dependents {
+		plan.Dependents = append(plan.Dependents, appRestartDependent{
+			Name:  dep.Name,
+			State: dep.State.String(),
+			Mode:  dep.Mode.String(),
+		})
+		if dep.Name == AppName {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s depends on %s and cannot be stopped by itself", AppName, name))
+		}
+	}
+
+	if info.State != gen.ApplicationStateRunning {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("application is not running (%s), it will only be started", info.State))
+	}
+	// stopping an application running in permanent mode shuts down the node
+	var permanent []gen.Atom
+	if info.State == gen.ApplicationStateRunning && info.Mode == gen.ApplicationModePermanent {
+		permanent = append(permanent, name)
+	}
+	if p.RestartDependents {
+		for _, dep := range dependents {
+			if dep.Mode == gen.ApplicationModePermanent {
+				permanent = append(permanent, dep.Name)
+			}
+		}
+	}
+	if len(permanent) > 0 {
+		plan.Warnings = append(plan.Warnings,
+			fmt.Sprintf("%v run in permanent mode: stopping them shuts down the node, allow_node_shutdown=true is required", permanent))
+	}
+	if len(dependents) > 0 && p.RestartDependents == false {
+		plan.Warnings = append(plan.Warnings, "dependent applications keep running while the application is restarted")
+	}
+
+	if p.RestartDependents {
+		for _, dep := range dependents {
+			plan.Steps = append(plan.Steps, fmt.Sprintf("stop %s", dep.Name))
+		}
+	}
+	if info.State == gen.ApplicationStateRunning {
+		plan.Steps = append(plan.Steps, fmt.Sprintf("stop %s (force: %v)", name, p.Force))
+	}
+	plan.Steps = append(plan.Steps, fmt.Sprintf("start %s (%s)", name, mode))
+	if p.RestartDependents {
+		for i := len(dependents) - 1; i >= 0; i-- {
+			plan.Steps = append(plan.Steps, fmt.Sprintf("start %s (%s)", dependents[i].Name, dependents[i].Mode))
+		}
+	}
+
+	if p.DryRun == false {
+		if len(permanent) > 0 && p.AllowNodeShutdown == false {
+			return nil, fmt.Errorf("app_restart: %v run in permanent mode, stopping them shuts down the node. Set allow_node_shutdown=true to proceed", permanent)
+		}
+		if p.RestartDependents {
+			for _, dep := range dependents {
+				if dep.Name == AppName {
+					return nil, fmt.Errorf("app_restart: %s depends on %s and cannot be stopped by itself", AppName, name)
+				}
+			}
+		}
+		down, err := restartApp(w, info, mode, p.Force, p.RestartDependents, dependents)
+		if err != nil {
+			if len(down) > 0 {
+				return nil, fmt.Errorf("app_restart: %w. Not running now: %v", err, down)
+			}
+			return nil, fmt.Errorf("app_restart: %w", err)
+		}
+	}
+
+	text, err := marshalResult(plan)
+	if err != nil {
+		return nil, err
+	}
+	return textResult(text), nil
+}
+
+// restartApp returns the applications it stopped and didn't start again if it fails
+func restartApp(w gen.Process, info gen.ApplicationInfo, mode gen.ApplicationMode, force bool, withDependents bool, dependents []gen.ApplicationInfo) ([]gen.Atom, error) {
+	var stopped []gen.Atom
+	if withDependents {
+		for _, dep := range dependents {
+			if err := stopApp(w, dep.Name, force); err != nil {
+				return stopped, fmt.Errorf("stop dependent %s: %w", dep.Name, err)
+			}
+			stopped = append(stopped, dep.Name)
+		}
+	}
+
+	if info.State == gen.ApplicationStateRunning {
+		if err := stopApp(w, info.Name, force); err != nil {
+			return stopped, fmt.Errorf("stop %s: %w", info.Name, err)
+		}
+		stopped = append(stopped, info.Name)
+	}
+	if err := startApp(w, info.Name, mode); err != nil {
+		if info.State == gen.ApplicationStateRunning {
+			return stopped, fmt.Errorf("%s stopped but failed to start: %w", info.Name, err)
+		}
+		return stopped, fmt.Errorf("start %s: %w", info.Name, err)
+	}
+	stopped = removeAtom(stopped, info.Name)
+
+	if withDependents {
+		for i := len(dependents) - 1; i >= 0; i-- {
+			dep := dependents[i]
+			if err := startApp(w, dep.Name, dep.Mode); err != nil {
+				return stopped, fmt.Errorf("dependent %s stopped but failed to start: %w", dep.Name, err)
+			}
+			stopped = removeAtom(stopped, dep.Name)
+		}
+	}
+	return nil, nil
+}
+
+func removeAtom(list []gen.Atom, atom gen.Atom) []gen.Atom {
+	for i, a := range list {
+		if a == atom {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+type appLoadParams struct {
+	Name string `json:"name"`
+}
+
+func toolAppLoad(w gen.Process, params json.RawMessage, loadable map[gen.Atom]gen.ApplicationBehavior) (any, error) {
+	var p appLoadParams
+	if len(params) > 0 {
+		json.Unmarshal(params, &p)
+	}
+
+	if p.Name == "" {
+		names := make([]string, 0, len(loadable))
+		for name := range loadable {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		text, err := marshalResult(names)
+		if err != nil {
+			return nil, err
+		}
+		return textResult(text), nil
+	}
+
+	behavior, ok := loadable[gen.Atom(p.Name)]
+	if ok == false {
+		return nil, fmt.Errorf("application %s is not in mcp.Options.LoadableApplications. Call app_load without name to list loadable applications", p.Name)
+	}
+
+	name, err := w.Node().ApplicationLoad(behavior)
+	if err != nil {
+		return nil, fmt.Errorf("app_load: %w", err)
+	}
+	return textResult(fmt.Sprintf("application %s loaded", name)), nil
+}
+
+type appUnloadParams struct {
+	Name string `json:"name"`
+}
+
+func toolAppUnload(w gen.Process, params json.RawMessage) (any, error) {
+	var p appUnloadParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	if err := w.Node().ApplicationUnload(gen.Atom(p.Name)); err != nil {
+		return nil, fmt.Errorf("app_unload: %w", err)
+	}
+	return textResult(fmt.Sprintf("application %s unloaded", p.Name)), nil
+}
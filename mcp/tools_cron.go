@@ -11,7 +11,7 @@ import (
 func registerCronTools(r *toolRegistry) {
 	r.register(ToolDefinition{
 		Name:        "cron_info",
-		Description: "Returns cron scheduler information: next run time, queued jobs count, all job details.",
+		Description: "Returns cron scheduler information: next run time, queued jobs count, all job details. Runnable marks the jobs cron_job_run_now can trigger.",
 		InputSchema: json.RawMessage(`{
 			"type": "object",
 			"properties": {},
@@ -22,7 +22,7 @@ func registerCronTools(r *toolRegistry) {
 
 	r.register(ToolDefinition{
 		Name:        "cron_job",
-		Description: "Returns detailed information about a specific cron job: spec, location, last run, last error.",
+		Description: "Returns detailed information about a specific cron job: spec, location, last run, last error, and whether cron_job_run_now can trigger it (Runnable).",
 		InputSchema: json.RawMessage(`{
 			"type": "object",
 			"properties": {
@@ -52,10 +52,43 @@ func registerCronTools(r *toolRegistry) {
 	})
 }
 
+// cronJobInfo is gen.CronJobInfo with the flag whether the job can be run on demand
+type cronJobInfo struct {
+	gen.CronJobInfo
+	Runnable bool // added with cron_job_add, see cron_job_run_now
+}
+
+// runnableCronJobs returns the jobs whose actions are kept by the store
+func runnableCronJobs(w gen.Process) map[gen.Atom]bool {
+	runnable := make(map[gen.Atom]bool)
+	result, err := w.Call(StoreName, storeCronList{})
+	if err != nil {
+		return runnable
+	}
+	names, _ := result.([]gen.Atom)
+	for _, name := range names {
+		runnable[name] = true
+	}
+	return runnable
+}
+
 func toolCronInfo(w gen.Process, params json.RawMessage) (any, error) {
 	cron := w.Node().Cron()
 	info := cron.Info()
-	text, err := marshalResult(info)
+
+	runnable := runnableCronJobs(w)
+	result := struct {
+		gen.CronInfo
+		Jobs []cronJobInfo
+	}{
+		CronInfo: info,
+		Jobs:     make([]cronJobInfo, 0, len(info.Jobs)),
+	}
+	for _, job := range info.Jobs {
+		result.Jobs = append(result.Jobs, cronJobInfo{CronJobInfo: job, Runnable: runnable[job.Name]})
+	}
+
+	text, err := marshalResult(result)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +110,8 @@ func toolCronJob(w gen.Process, params json.RawMessage) (any, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cron_job: %w", err)
 	}
-	text, err := marshalResult(info)
+	job := cronJobInfo{CronJobInfo: info, Runnable: runnableCronJobs(w)[info.Name]}
+	text, err := marshalResult(job)
 	if err != nil {
 		return nil, err
 	}
@@ -105,3 +139,253 @@ func toolCronSchedule(w gen.Process, params json.RawMessage) (any, error) {
 	}
 	return textResult(text), nil
 }
+
+func registerCronActionTools(r *toolRegistry) {
+	r.registerAction(ToolDefinition{
+		Name:        "cron_job_run_now",
+		Description: "Runs a cron job immediately, outside of its schedule. Only jobs added with cron_job_add can be triggered: the action of other jobs is not accessible via the gen.Cron interface.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Job name"
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolCronJobRunNow,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "cron_job_enable",
+		Description: "Enables a previously disabled cron job. The job is scheduled again according to its spec.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Job name"
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolCronJobEnable,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "cron_job_disable",
+		Description: "Disables a cron job. The job stays registered (visible in cron_info) but is not run until enabled with cron_job_enable. Use this to pause a misbehaving job during an incident.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Job name"
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolCronJobDisable,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "cron_job_add",
+		Description: "Adds a cron job that sends a message to a process according to the crontab spec. If type_name is specified, the message is a typed Go struct constructed from the EDF type registry (see message_types). Without type_name, the raw JSON value is sent.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Job name (must be unique)"
+				},
+				"spec": {
+					"type": "string",
+					"description": "Time spec in crontab format, e.g. '*/5 * * * *' (every 5 minutes)"
+				},
+				"location": {
+					"type": "string",
+					"description": "Time zone name from the IANA database, e.g. 'Europe/Berlin' (default: local time zone of the node)"
+				},
+				"to": {
+					"type": "string",
+					"description": "Target: registered process name or PID string"
+				},
+				"type_name": {
+					"type": "string",
+					"description": "EDF-registered type name (full or short). If empty, sends raw JSON value"
+				},
+				"message": {
+					"type": "object",
+					"description": "Message data: JSON object with field values (for typed) or any JSON value (for raw)"
+				}
+			},
+			"required": ["name", "spec", "to", "message"]
+		}`),
+		handler: toolCronJobAdd,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "cron_job_remove",
+		Description: "Removes a cron job from the scheduler.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Job name"
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolCronJobRemove,
+	})
+}
+
+// cronActionSend is the action of the jobs added via cron_job_add.
+// Sends the prepared message to the target on each run.
+type cronActionSend struct {
+	to       any
+	message  any
+	typeName string
+}
+
+func (a cronActionSend) Do(job gen.Atom, node gen.Node, actionTime time.Time) error {
+	return node.Send(a.to, a.message)
+}
+
+func (a cronActionSend) Info() string {
+	return fmt.Sprintf("send %s to %v (added via MCP)", a.typeName, a.to)
+}
+
+func toolCronJobRunNow(w gen.Process, params json.RawMessage) (any, error) {
+	var p cronJobParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	name := gen.Atom(p.Name)
+	if _, err := w.Node().Cron().JobInfo(name); err != nil {
+		return nil, fmt.Errorf("cron_job_run_now: %w", err)
+	}
+
+	result, err := w.Call(StoreName, storeCronGet{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("cron_job_run_now: %w", err)
+	}
+	action, ok := result.(gen.CronAction)
+	if ok == false {
+		return nil, fmt.Errorf("job %s was not added via cron_job_add, its action is not accessible and cannot be triggered", p.Name)
+	}
+
+	if err := action.Do(name, w.Node(), time.Now()); err != nil {
+		return nil, fmt.Errorf("cron_job_run_now: %w", err)
+	}
+	return textResult(fmt.Sprintf("cron job %s triggered: %s", p.Name, action.Info())), nil
+}
+
+func toolCronJobEnable(w gen.Process, params json.RawMessage) (any, error) {
+	var p cronJobParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	if err := w.Node().Cron().EnableJob(gen.Atom(p.Name)); err != nil {
+		return nil, fmt.Errorf("cron_job_enable: %w", err)
+	}
+	return textResult(fmt.Sprintf("cron job %s enabled", p.Name)), nil
+}
+
+func toolCronJobDisable(w gen.Process, params json.RawMessage) (any, error) {
+	var p cronJobParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	if err := w.Node().Cron().DisableJob(gen.Atom(p.Name)); err != nil {
+		return nil, fmt.Errorf("cron_job_disable: %w", err)
+	}
+	return textResult(fmt.Sprintf("cron job %s disabled", p.Name)), nil
+}
+
+type cronJobAddParams struct {
+	Name     string          `json:"name"`
+	Spec     string          `json:"spec"`
+	Location string          `json:"location"`
+	To       string          `json:"to"`
+	TypeName string          `json:"type_name"`
+	Message  json.RawMessage `json:"message"`
+}
+
+func toolCronJobAdd(w gen.Process, params json.RawMessage) (any, error) {
+	var p cronJobAddParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+	if p.Name == "" || p.Spec == "" || p.To == "" {
+		return nil, fmt.Errorf("name, spec and to are required")
+	}
+
+	location := time.Local
+	if p.Location != "" {
+		loc, err := time.LoadLocation(p.Location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid location: %w", err)
+		}
+		location = loc
+	}
+
+	message, err := buildMessage(p.TypeName, p.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	typeName := p.TypeName
+	if typeName == "" {
+		typeName = fmt.Sprintf("%T", message)
+	}
+	action := cronActionSend{
+		to:       resolveTarget(w, p.To),
+		message:  message,
+		typeName: typeName,
+	}
+
+	job := gen.CronJob{
+		Name:     gen.Atom(p.Name),
+		Spec:     p.Spec,
+		Location: location,
+		Action:   action,
+	}
+	if err := w.Node().Cron().AddJob(job); err != nil {
+		return nil, fmt.Errorf("cron_job_add: %w", err)
+	}
+
+	if _, err := w.Call(StoreName, storeCronPut{Name: job.Name, Spec: job.Spec, Action: action}); err != nil {
+		// the job is scheduled anyway, only run_now is unavailable for it
+		w.Log().Warning("unable to keep action of cron job %s: %s", p.Name, err)
+	}
+
+	info, err := w.Node().Cron().JobInfo(job.Name)
+	if err != nil {
+		return textResult(fmt.Sprintf("cron job %s added", p.Name)), nil
+	}
+	text, err := marshalResult(info)
+	if err != nil {
+		return nil, err
+	}
+	return textResult(text), nil
+}
+
+func toolCronJobRemove(w gen.Process, params json.RawMessage) (any, error) {
+	var p cronJobParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	name := gen.Atom(p.Name)
+	if err := w.Node().Cron().RemoveJob(name); err != nil {
+		return nil, fmt.Errorf("cron_job_remove: %w", err)
+	}
+	w.Call(StoreName, storeCronDelete{Name: name})
+	return textResult(fmt.Sprintf("cron job %s removed", p.Name)), nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"ergo.services/ergo/gen"
 )
@@ -261,3 +262,183 @@ func toolEventInfo(w gen.Process, params json.RawMessage) (any, error) {
 	}
 	return textResult(text), nil
 }
+
+func registerEventActionTools(r *toolRegistry) {
+	r.registerAction(ToolDefinition{
+		Name:        "event_register",
+		Description: "Registers a temporary event owned by the MCP application, for reproducing subscriber behaviour (e.g. in staging). Processes can subscribe to it with LinkEvent/MonitorEvent, messages are published with event_publish. The event is unregistered automatically when ttl_sec expires. Without name, lists events registered via MCP.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Event name (must not be registered on the node yet). Omit to list events registered via MCP"
+				},
+				"notify": {
+					"type": "boolean",
+					"description": "Notify mode: the owner gets notified about the first subscriber and the last unsubscribe. Default: false"
+				},
+				"buffer": {
+					"type": "integer",
+					"description": "Number of last published messages delivered to new subscribers (default: 0, no buffer)"
+				},
+				"ttl_sec": {
+					"type": "integer",
+					"description": "Seconds until the event is unregistered automatically (default: 600, max: 86400)"
+				}
+			}
+		}`),
+		handler: toolEventRegister,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "event_publish",
+		Description: "Publishes a message to the subscribers of an event registered with event_register (only the event owner can publish). If type_name is specified, constructs a typed Go struct using reflection from the EDF type registry. Without type_name, sends the raw JSON value (remote subscribers require an EDF-registered type).",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Event name"
+				},
+				"type_name": {
+					"type": "string",
+					"description": "EDF-registered type name (full or short). If empty, sends raw JSON value"
+				},
+				"message": {
+					"type": "object",
+					"description": "Message data: JSON object with field values (for typed) or any JSON value (for raw)"
+				}
+			},
+			"required": ["name", "message"]
+		}`),
+		handler: toolEventPublish,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "event_unregister",
+		Description: "Unregisters an event registered with event_register before its TTL expires. Subscribers receive a termination notification (MessageDownEvent/exit signal).",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Event name"
+				}
+			},
+			"required": ["name"]
+		}`),
+		handler: toolEventUnregister,
+	})
+}
+
+type eventRegisterParams struct {
+	Name   string `json:"name"`
+	Notify bool   `json:"notify"`
+	Buffer int    `json:"buffer"`
+	TTLSec int    `json:"ttl_sec"`
+}
+
+func toolEventRegister(w gen.Process, params json.RawMessage) (any, error) {
+	var p eventRegisterParams
+	if len(params) > 0 {
+		json.Unmarshal(params, &p)
+	}
+
+	if p.Name == "" {
+		result, err := w.Call(StoreName, storeEventList{})
+		if err != nil {
+			return nil, fmt.Errorf("event_register: %w", err)
+		}
+		text, err := marshalResult(result)
+		if err != nil {
+			return nil, err
+		}
+		return textResult(text), nil
+	}
+
+	if p.TTLSec < 1 {
+		p.TTLSec = 600
+	}
+	if p.TTLSec > 86400 {
+		p.TTLSec = 86400
+	}
+
+	result, err := w.Call(StoreName, storeEventRegister{
+		Name: gen.Atom(p.Name),
+		Options: gen.EventOptions{
+			Notify: p.Notify,
+			Buffer: p.Buffer,
+		},
+		TTL: time.Duration(p.TTLSec) * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("event_register: %w", err)
+	}
+	if e, ok := result.(error); ok {
+		return nil, fmt.Errorf("event_register: %w", e)
+	}
+
+	text, err := marshalResult(map[string]any{
+		"event":   fmt.Sprintf("%s@%s", p.Name, w.Node().Name()),
+		"notify":  p.Notify,
+		"buffer":  p.Buffer,
+		"ttl_sec": p.TTLSec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return textResult(text), nil
+}
+
+type eventPublishParams struct {
+	Name     string          `json:"name"`
+	TypeName string          `json:"type_name"`
+	Message  json.RawMessage `json:"message"`
+}
+
+func toolEventPublish(w gen.Process, params json.RawMessage) (any, error) {
+	var p eventPublishParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	message, err := buildMessage(p.TypeName, p.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := w.Call(StoreName, storeEventPublish{Name: gen.Atom(p.Name), Message: message})
+	if err != nil {
+		return nil, fmt.Errorf("event_publish: %w", err)
+	}
+	if e, ok := result.(error); ok {
+		return nil, fmt.Errorf("event_publish: %w", e)
+	}
+
+	typeName := p.TypeName
+	if typeName == "" {
+		typeName = fmt.Sprintf("%T", message)
+	}
+	return textResult(fmt.Sprintf("message published to event %s (type: %s)", p.Name, typeName)), nil
+}
+
+type eventUnregisterParams struct {
+	Name string `json:"name"`
+}
+
+func toolEventUnregister(w gen.Process, params json.RawMessage) (any, error) {
+	var p eventUnregisterParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	result, err := w.Call(StoreName, storeEventUnregister{Name: gen.Atom(p.Name)})
+	if err != nil {
+		return nil, fmt.Errorf("event_unregister: %w", err)
+	}
+	if e, ok := result.(error); ok {
+		return nil, fmt.Errorf("event_unregister: %w", e)
+	}
+	return textResult(fmt.Sprintf("event %s unregistered", p.Name)), nil
+}
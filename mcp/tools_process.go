@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 
+	"ergo.services/ergo/app/system/inspect"
 	"ergo.services/ergo/gen"
 )
 
@@ -194,6 +195,22 @@ func registerProcessTools(r *toolRegistry) {
 		}`),
 		handler: toolMetaInspect,
 	})
+
+	r.register(ToolDefinition{
+		Name:        "process_settings",
+		Description: "Returns the current per-process delivery settings: send priority, compression (enabled, type, level, threshold), keep network order, important delivery. For a meta process (by alias) returns its send priority. Change them with process_set_* tools.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"target": {
+					"type": "string",
+					"description": "Process PID string, registered process name, or meta process alias string"
+				}
+			},
+			"required": ["target"]
+		}`),
+		handler: toolProcessSettings,
+	})
 }
 
 type processListParams struct {
@@ -540,3 +557,348 @@ func toolMetaInspect(w gen.Process, params json.RawMessage) (any, error) {
 	}
 	return textResult(text), nil
 }
+
+// resolveProcess resolves a PID string or a registered name to PID
+func resolveProcess(w gen.Process, target string) (gen.PID, error) {
+	pid, err := parsePID(w.Node().Name(), w.Node().Creation(), target)
+	if err == nil {
+		return pid, nil
+	}
+	pid, err = w.Node().ProcessPID(gen.Atom(target))
+	if err != nil {
+		return pid, fmt.Errorf("cannot resolve target %q: not a valid PID or registered name", target)
+	}
+	return pid, nil
+}
+
+type processSettingsParams struct {
+	Target string `json:"target"`
+}
+
+func toolProcessSettings(w gen.Process, params json.RawMessage) (any, error) {
+	var p processSettingsParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	if alias, err := parseAlias(w.Node().Name(), w.Node().Creation(), p.Target); err == nil {
+		info, err := w.Node().MetaInfo(alias)
+		if err != nil {
+			return nil, fmt.Errorf("process_settings: %w", err)
+		}
+		text, err := marshalResult(map[string]any{
+			"meta":          alias.String(),
+			"behavior":      info.Behavior,
+			"send_priority": fmt.Sprintf("%v", info.MessagePriority),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return textResult(text), nil
+	}
+
+	pid, err := resolveProcess(w, p.Target)
+	if err != nil {
+		return nil, err
+	}
+	info, err := w.Node().ProcessInfo(pid)
+	if err != nil {
+		return nil, fmt.Errorf("process_settings: %w", err)
+	}
+
+	text, err := marshalResult(map[string]any{
+		"pid":           pid.String(),
+		"name":          info.Name,
+		"behavior":      info.Behavior,
+		"send_priority": fmt.Sprintf("%v", info.MessagePriority),
+		"compression": map[string]any{
+			"enabled":   info.Compression.Enable,
+			"type":      fmt.Sprintf("%v", info.Compression.Type),
+			"level":     fmt.Sprintf("%v", info.Compression.Level),
+			"threshold": info.Compression.Threshold,
+		},
+		"keep_network_order": info.KeepNetworkOrder,
+		"important_delivery": info.ImportantDelivery,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return textResult(text), nil
+}
+
+func registerProcessActionTools(r *toolRegistry) {
+	r.registerAction(ToolDefinition{
+		Name:        "process_set_send_priority",
+		Description: "Sets the priority of messages sent by a process or a meta process. Delivered to the Urgent (max), System (high) or Main (normal) mailbox queue of the recipient. Verify the effect with process_settings and a sampler.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"target": {
+					"type": "string",
+					"description": "Process PID string, registered process name, or meta process alias string"
+				},
+				"priority": {
+					"type": "string",
+					"description": "Send priority",
+					"enum": ["normal", "high", "max"]
+				}
+			},
+			"required": ["target", "priority"]
+		}`),
+		handler: toolProcessSetSendPriority,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "process_set_compression",
+		Description: "Changes compression of messages sent by a process to remote nodes. Only the given fields are changed. Compression is applied to messages larger than threshold.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"target": {
+					"type": "string",
+					"description": "Process PID string or registered process name"
+				},
+				"enabled": {
+					"type": "boolean",
+					"description": "Enable or disable compression"
+				},
+				"type": {
+					"type": "string",
+					"description": "Compression algorithm",
+					"enum": ["gzip", "lzw", "zlib"]
+				},
+				"level": {
+					"type": "string",
+					"description": "Compression level",
+					"enum": ["default", "best_speed", "best_size"]
+				},
+				"threshold": {
+					"type": "integer",
+					"description": "Minimal message size in bytes to be compressed"
+				}
+			},
+			"required": ["target"]
+		}`),
+		handler: toolProcessSetCompression,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "process_set_keep_network_order",
+		Description: "Enables or disables keeping the order of messages sent by a process to remote nodes. Disabling allows parallel delivery through the connection pool (higher throughput, no ordering guarantee).",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"target": {
+					"type": "string",
+					"description": "Process PID string or registered process name"
+				},
+				"order": {
+					"type": "boolean",
+					"description": "Keep network order"
+				}
+			},
+			"required": ["target", "order"]
+		}`),
+		handler: toolProcessSetKeepNetworkOrder,
+	})
+
+	r.registerAction(ToolDefinition{
+		Name:        "process_set_important_delivery",
+		Description: "Enables or disables important delivery for messages sent by a process to remote nodes: the sender gets ErrProcessUnknown immediately if the remote target does not exist, instead of silently losing the message.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"target": {
+					"type": "string",
+					"description": "Process PID string or registered process name"
+				},
+				"important": {
+					"type": "boolean",
+					"description": "Important delivery"
+				}
+			},
+			"required": ["target", "important"]
+		}`),
+		handler: toolProcessSetImportantDelivery,
+	})
+}
+
+// inspectDo sends a RequestDo* request to the system_inspect process of the local node.
+// Process settings are changeable via system_inspect only (same as Observer does).
+func inspectDo(w gen.Process, request any) error {
+	result, err := w.Call(gen.ProcessID{Name: inspect.Name, Node: w.Node().Name()}, request)
+	if err != nil {
+		return err
+	}
+	if r, ok := result.(inspect.ResponseDoSet); ok {
+		return r.Error
+	}
+	return fmt.Errorf("unexpected response from %s: %T", inspect.Name, result)
+}
+
+func parseSendPriority(s string) (gen.MessagePriority, error) {
+	switch strings.ToLower(s) {
+	case "normal":
+		return gen.MessagePriorityNormal, nil
+	case "high":
+		return gen.MessagePriorityHigh, nil
+	case "max":
+		return gen.MessagePriorityMax, nil
+	}
+	return gen.MessagePriorityNormal, fmt.Errorf("unknown priority: %s", s)
+}
+
+type processSetSendPriorityParams struct {
+	Target   string `json:"target"`
+	Priority string `json:"priority"`
+}
+
+func toolProcessSetSendPriority(w gen.Process, params json.RawMessage) (any, error) {
+	var p processSetSendPriorityParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	priority, err := parseSendPriority(p.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	if alias, err := parseAlias(w.Node().Name(), w.Node().Creation(), p.Target); err == nil {
+		if err := inspectDo(w, inspect.RequestDoSetMetaSendPriority{Meta: alias, Priority: priority}); err != nil {
+			return nil, fmt.Errorf("process_set_send_priority meta %s: %w", p.Target, err)
+		}
+		return textResult(fmt.Sprintf("meta %s send priority set to %s", p.Target, p.Priority)), nil
+	}
+
+	pid, err := resolveProcess(w, p.Target)
+	if err != nil {
+		return nil, err
+	}
+	if err := inspectDo(w, inspect.RequestDoSetProcessSendPriority{PID: pid, Priority: priority}); err != nil {
+		return nil, fmt.Errorf("process_set_send_priority %s: %w", p.Target, err)
+	}
+	return textResult(fmt.Sprintf("process %s send priority set to %s", pid, p.Priority)), nil
+}
+
+type processSetCompressionParams struct {
+	Target    string `json:"target"`
+	Enabled   *bool  `json:"enabled"`
+	Type      string `json:"type"`
+	Level     string `json:"level"`
+	Threshold *int   `json:"threshold"`
+}
+
+func toolProcessSetCompression(w gen.Process, params json.RawMessage) (any, error) {
+	var p processSetCompressionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	pid, err := resolveProcess(w, p.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate everything before changing anything
+	var requests []any
+	var changes []string
+
+	if p.Enabled != nil {
+		requests = append(requests, inspect.RequestDoSetProcessCompression{PID: pid, Enabled: *p.Enabled})
+		changes = append(changes, fmt.Sprintf("enabled=%v", *p.Enabled))
+	}
+
+	if p.Type != "" {
+		var ctype gen.CompressionType
+		switch strings.ToLower(p.Type) {
+		case "gzip":
+			ctype = gen.CompressionTypeGZIP
+		case "lzw":
+			ctype = gen.CompressionTypeLZW
+		case "zlib":
+			ctype = gen.CompressionTypeZLIB
+		default:
+			return nil, fmt.Errorf("unknown compression type: %s", p.Type)
+		}
+		requests = append(requests, inspect.RequestDoSetProcessCompressionType{PID: pid, Type: ctype})
+		changes = append(changes, fmt.Sprintf("type=%s", p.Type))
+	}
+
+	if p.Level != "" {
+		var level gen.CompressionLevel
+		switch strings.ToLower(p.Level) {
+		case "default":
+			level = gen.CompressionDefault
+		case "best_speed":
+			level = gen.CompressionBestSpeed
+		case "best_size":
+			level = gen.CompressionBestSize
+		default:
+			return nil, fmt.Errorf("unknown compression level: %s", p.Level)
+		}
+		requests = append(requests, inspect.RequestDoSetProcessCompressionLevel{PID: pid, Level: level})
+		changes = append(changes, fmt.Sprintf("level=%s", p.Level))
+	}
+
+	if p.Threshold != nil {
+		if *p.Threshold < 0 {
+			return nil, fmt.Errorf("threshold must be >= 0")
+		}
+		requests = append(requests, inspect.RequestDoSetProcessCompressionThreshold{PID: pid, Threshold: *p.Threshold})
+		changes = append(changes, fmt.Sprintf("threshold=%d", *p.Threshold))
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("nothing to change: specify enabled, type, level or threshold")
+	}
+
+	for i, request := range requests {
+		if err := inspectDo(w, request); err != nil {
+			return nil, fmt.Errorf("process_set_compression %s (%s): %w", p.Target, changes[i], err)
+		}
+	}
+	return textResult(fmt.Sprintf("process %s compression changed: %s", pid, strings.Join(changes, ", "))), nil
+}
+
+type processSetKeepNetworkOrderParams struct {
+	Target string `json:"target"`
+	Order  bool   `json:"order"`
+}
+
+func toolProcessSetKeepNetworkOrder(w gen.Process, params json.RawMessage) (any, error) {
+	var p processSetKeepNetworkOrderParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	pid, err := resolveProcess(w, p.Target)
+	if err != nil {
+		return nil, err
+	}
+	if err := inspectDo(w, inspect.RequestDoSetProcessKeepNetworkOrder{PID: pid, Order: p.Order}); err != nil {
+		return nil, fmt.Errorf("process_set_keep_network_order %s: %w", p.Target, err)
+	}
+	return textResult(fmt.Sprintf("process %s keep network order set to %v", pid, p.Order)), nil
+}
+
+type processSetImportantDeliveryParams struct {
+	Target    string `json:"target"`
+	Important bool   `json:"important"`
+}
+
+func toolProcessSetImportantDelivery(w gen.Process, params json.RawMessage) (any, error) {
+	var p processSetImportantDeliveryParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	pid, err := resolveProcess(w, p.Target)
+	if err != nil {
+		return nil, err
+	}
+	if err := inspectDo(w, inspect.RequestDoSetProcessImportantDelivery{PID: pid, Important: p.Important}); err != nil {
+		return nil, fmt.Errorf("process_set_important_delivery %s: %w", p.Target, err)
+	}
+	return textResult(fmt.Sprintf("process %s important delivery set to %v", pid, p.Important)), nil
+}
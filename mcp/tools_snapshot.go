@@ -0,0 +1,527 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"ergo.services/ergo/gen"
+)
+
+func registerSnapshotTools(r *toolRegistry) {
+	r.register(ToolDefinition{
+		Name:        "node_snapshot",
+		Description: "Captures the whole node state (processes, applications, events, network peers, loggers, cron jobs) into a named snapshot held by the MCP application. Compare snapshots with node_diff to find out what changed between two moments. Up to 16 snapshots are kept, the oldest one is evicted first.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "Snapshot name (default: generated from the current time). Existing snapshot with the same name is replaced"
+				}
+			}
+		}`),
+		handler: toolNodeSnapshot,
+	})
+
+	r.register(ToolDefinition{
+		Name:        "node_snapshot_list",
+		Description: "Lists stored snapshots with creation time, age and captured item counts.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {},
+			"additionalProperties": false
+		}`),
+		handler: toolNodeSnapshotList,
+	})
+
+	r.register(ToolDefinition{
+		Name:        "node_diff",
+		Description: "Compares two snapshots, or a snapshot against the current node state. Reports new and terminated processes, mailbox growth, message throughput between the snapshots (messages in/out and the average per second), applications that changed state, events registered/unregistered, connections that came or went, logger and cron job changes.",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"from": {
+					"type": "string",
+					"description": "Name of the baseline snapshot"
+				},
+				"to": {
+					"type": "string",
+					"description": "Name of the snapshot to compare with. Omit to compare against the current node state"
+				},
+				"limit": {
+					"type": "integer",
+					"description": "Maximum number of entries in each process section (default: 20, 0 = all)"
+				},
+				"min_mailbox_growth": {
+					"type": "integer",
+					"description": "Minimum mailbox depth increase to report a process in mailbox_growth (default: 1)"
+				}
+			},
+			"required": ["from"]
+		}`),
+		handler: toolNodeDiff,
+	})
+}
+
+// nodeSnapshot is the node state captured at a moment in time.
+type nodeSnapshot struct {
+	Name        string
+	CreatedAt   time.Time
+	Processes   map[gen.PID]gen.ProcessShortInfo
+	Apps        map[gen.Atom]gen.ApplicationInfo
+	Events      map[gen.Event]gen.EventInfo
+	Connections map[gen.Atom]gen.RemoteNodeInfo
+	Loggers     map[string][]gen.LogLevel
+	CronJobs    map[gen.Atom]gen.CronJobInfo
+}
+
+func (s *nodeSnapshot) summary() snapshotSummary {
+	return snapshotSummary{
+		Name:        s.Name,
+		CreatedAt:   s.CreatedAt,
+		Age:         time.Since(s.CreatedAt).Truncate(time.Second).String(),
+		Processes:   len(s.Processes),
+		Apps:        len(s.Apps),
+		Events:      len(s.Events),
+		Connections: len(s.Connections),
+		Loggers:     len(s.Loggers),
+		CronJobs:    len(s.CronJobs),
+	}
+}
+
+// captureSnapshot collects the current node state.
+func captureSnapshot(w gen.Process, name string) *nodeSnapshot {
+	node := w.Node()
+	s := &nodeSnapshot{
+		Name:        name,
+		CreatedAt:   time.Now(),
+		Processes:   make(map[gen.PID]gen.ProcessShortInfo),
+		Apps:        make(map[gen.Atom]gen.ApplicationInfo),
+		Events:      make(map[gen.Event]gen.EventInfo),
+		Connections: make(map[gen.Atom]gen.RemoteNodeInfo),
+		Loggers:     make(map[string][]gen.LogLevel),
+		CronJobs:    make(map[gen.Atom]gen.CronJobInfo),
+	}
+
+	node.ProcessRangeShortInfo(func(info gen.ProcessShortInfo) bool {
+		s.Processes[info.PID] = info
+		return true
+	})
+
+	for _, app := range node.Applications() {
+		info, err := node.ApplicationInfo(app)
+		if err != nil {
+			continue
+		}
+		s.Apps[app] = info
+	}
+
+	node.EventRangeInfo(func(info gen.EventInfo) bool {
+		s.Events[info.Event] = info
+		return true
+	})
+
+	net := node.Network()
+	for _, peer := range net.Nodes() {
+		remote, err := net.Node(peer)
+		if err != nil {
+			continue
+		}
+		s.Connections[peer] = remote.Info()
+	}
+
+	for _, logger := range node.Loggers() {
+		s.Loggers[logger] = node.LoggerLevels(logger)
+	}
+
+	for _, job := range node.Cron().Info().Jobs {
+		s.CronJobs[job.Name] = job
+	}
+
+	return s
+}
+
+type nodeSnapshotParams struct {
+	Name string `json:"name"`
+}
+
+func toolNodeSnapshot(w gen.Process, params json.RawMessage) (any, error) {
+	var p nodeSnapshotParams
+	if len(params) > 0 {
+		json.Unmarshal(params, &p)
+	}
+	if p.Name == "" {
+		p.Name = time.Now().Format("20060102T150405.000")
+	}
+
+	snapshot := captureSnapshot(w, p.Name)
+	if _, err := w.Call(StoreName, storeSnapshotPut{Snapshot: snapshot}); err != nil {
+		return nil, fmt.Errorf("node_snapshot: %w", err)
+	}
+
+	text, err := marshalResult(snapshot.summary())
+	if err != nil {
+		return nil, err
+	}
+	return textResult(text), nil
+}
+
+func toolNodeSnapshotList(w gen.Process, params json.RawMessage) (any, error) {
+	result, err := w.Call(StoreName, storeSnapshotList{})
+	if err != nil {
+		return nil, fmt.Errorf("node_snapshot_list: %w", err)
+	}
+	text, err := marshalResult(result)
+	if err != nil {
+		return nil, err
+	}
+	return textResult(text), nil
+}
+
+// fetchSnapshot reads a named snapshot from the store.
+func fetchSnapshot(w gen.Process, name string) (*nodeSnapshot, error) {
+	result, err := w.Call(StoreName, storeSnapshotGet{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	switch r := result.(type) {
+	case *nodeSnapshot:
+		return r, nil
+	case error:
+		return nil, r
+	}
+	return nil, fmt.Errorf("unexpected response from %s", StoreName)
+}
+
+type nodeDiffParams struct {
+	From             string `json:"from"`
+	To               string `json:"to"`
+	Limit            *int   `json:"limit"`
+	MinMailboxGrowth uint64 `json:"min_mailbox_growth"`
+}
+
+type diffProcess struct {
+	PID         gen.PID  `json:"pid"`
+	Name        gen.Atom `json:"name,omitempty"`
+	Behavior    string   `json:"behavior"`
+	Application gen.Atom `json:"application,omitempty"`
+	Mailbox     uint64   `json:"mailbox"`
+	Uptime      int64    `json:"uptime,omitempty"`
+}
+
+type diffMailbox struct {
+	PID      gen.PID  `json:"pid"`
+	Name     gen.Atom `json:"name,omitempty"`
+	Behavior string   `json:"behavior"`
+	Before   uint64   `json:"before"`
+	After    uint64   `json:"after"`
+	Growth   uint64   `json:"growth"`
+}
+
+// connectionUptimeTolerance (seconds) absorbs the rounding of the connection uptime
+// and the time the snapshot takes when detecting reconnects
+const connectionUptimeTolerance = 2
+
+// diffThroughput is the number of messages a process handled between the snapshots
+// and the average rate over that interval
+type diffThroughput struct {
+	PID         gen.PID  `json:"pid"`
+	Name        gen.Atom `json:"name,omitempty"`
+	Behavior    string   `json:"behavior"`
+	MessagesIn  uint64   `json:"messages_in"`
+	MessagesOut uint64   `json:"messages_out"`
+	InPerSec    float64  `json:"in_per_sec"`
+	OutPerSec   float64  `json:"out_per_sec"`
+}
+
+type diffApp struct {
+	Name   gen.Atom `json:"name"`
+	Before string   `json:"before"`
+	After  string   `json:"after"`
+}
+
+type diffConnection struct {
+	Node   gen.Atom `json:"node"`
+	Uptime int64    `json:"connection_uptime,omitempty"`
+}
+
+type diffCronJob struct {
+	Name   gen.Atom `json:"name"`
+	Change string   `json:"change"`
+}
+
+type nodeDiffResult struct {
+	From               string           `json:"from"`
+	To                 string           `json:"to"`
+	Elapsed            string           `json:"elapsed"`
+	ProcessesBefore    int              `json:"processes_before"`
+	ProcessesAfter     int              `json:"processes_after"`
+	NewProcesses       []diffProcess    `json:"new_processes"`
+	NewProcessesTotal  int              `json:"new_processes_total"`
+	Terminated         []diffProcess    `json:"terminated_processes"`
+	TerminatedTotal    int              `json:"terminated_processes_total"`
+	MailboxGrowth      []diffMailbox    `json:"mailbox_growth"`
+	MessageThroughput  []diffThroughput `json:"message_throughput"`
+	AppsChanged        []diffApp        `json:"applications_changed"`
+	EventsRegistered   []string         `json:"events_registered"`
+	EventsUnregistered []string         `json:"events_unregistered"`
+	ConnectionsUp      []diffConnection `json:"connections_up"`
+	ConnectionsDown    []diffConnection `json:"connections_down"`
+	LoggersAdded       []string         `json:"loggers_added"`
+	LoggersRemoved     []string         `json:"loggers_removed"`
+	CronJobsChanged    []diffCronJob    `json:"cron_jobs_changed"`
+}
+
+func toolNodeDiff(w gen.Process, params json.RawMessage) (any, error) {
+	var p nodeDiffParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+	if p.From == "" {
+		return nil, fmt.Errorf("from is required")
+	}
+	limit := 20
+	if p.Limit != nil {
+		limit = *p.Limit
+	}
+	if p.MinMailboxGrowth < 1 {
+		p.MinMailboxGrowth = 1
+	}
+
+	before, err := fetchSnapshot(w, p.From)
+	if err != nil {
+		return nil, fmt.Errorf("node_diff: %w", err)
+	}
+
+	var after *nodeSnapshot
+	if p.To == "" {
+		after = captureSnapshot(w, "now")
+	} else {
+		after, err = fetchSnapshot(w, p.To)
+		if err != nil {
+			return nil, fmt.Errorf("node_diff: %w", err)
+		}
+	}
+
+	result := diffSnapshots(before, after, limit, p.MinMailboxGrowth)
+	text, err := marshalResult(result)
+	if err != nil {
+		return nil, err
+	}
+	return textResult(text), nil
+}
+
+func diffSnapshots(before, after *nodeSnapshot, limit int, minGrowth uint64) nodeDiffResult {
+	elapsed := after.CreatedAt.Sub(before.CreatedAt)
+	result := nodeDiffResult{
+		From:            before.Name,
+		To:              after.Name,
+		Elapsed:         elapsed.Truncate(time.Millisecond).String(),
+		ProcessesBefore: len(before.Processes),
+		ProcessesAfter:  len(after.Processes),
+	}
+
+	seconds := elapsed.Seconds()
+	if seconds < 0 {
+		seconds = -seconds
+	}
+
+	// processes
+	for pid, info := range after.Processes {
+		prev, ok := before.Processes[pid]
+		if ok == false {
+			result.NewProcesses = append(result.NewProcesses, newDiffProcess(info))
+			continue
+		}
+
+		if info.MessagesMailbox >= prev.MessagesMailbox+minGrowth {
+			result.MailboxGrowth = append(result.MailboxGrowth, diffMailbox{
+				PID:      pid,
+				Name:     info.Name,
+				Behavior: info.Behavior,
+				Before:   prev.MessagesMailbox,
+				After:    info.MessagesMailbox,
+				Growth:   info.MessagesMailbox - prev.MessagesMailbox,
+			})
+		}
+
+		if seconds > 0 && info.MessagesIn >= prev.MessagesIn && info.MessagesOut >= prev.MessagesOut {
+			in := info.MessagesIn - prev.MessagesIn
+			out := info.MessagesOut - prev.MessagesOut
+			if in > 0 || out > 0 {
+				result.MessageThroughput = append(result.MessageThroughput, diffThroughput{
+					PID:         pid,
+					Name:        info.Name,
+					Behavior:    info.Behavior,
+					MessagesIn:  in,
+					MessagesOut: out,
+					InPerSec:    float64(in) / seconds,
+					OutPerSec:   float64(out) / seconds,
+				})
+			}
+		}
+	}
+	for pid, info := range before.Processes {
+		if _, ok := after.Processes[pid]; ok == false {
+			result.Terminated = append(result.Terminated, newDiffProcess(info))
+		}
+	}
+
+	result.NewProcessesTotal = len(result.NewProcesses)
+	result.TerminatedTotal = len(result.Terminated)
+
+	sort.Slice(result.NewProcesses, func(i, j int) bool {
+		return result.NewProcesses[i].Mailbox > result.NewProcesses[j].Mailbox
+	})
+	sort.Slice(result.Terminated, func(i, j int) bool {
+		return result.Terminated[i].PID.ID < result.Terminated[j].PID.ID
+	})
+	sort.Slice(result.MailboxGrowth, func(i, j int) bool {
+		return result.MailboxGrowth[i].Growth > result.MailboxGrowth[j].Growth
+	})
+	sort.Slice(result.MessageThroughput, func(i, j int) bool {
+		return result.MessageThroughput[i].InPerSec+result.MessageThroughput[i].OutPerSec >
+			result.MessageThroughput[j].InPerSec+result.MessageThroughput[j].OutPerSec
+	})
+
+	if limit > 0 {
+		if len(result.NewProcesses) > limit {
+			result.NewProcesses = result.NewProcesses[:limit]
+		}
+		if len(result.Terminated) > limit {
+			result.Terminated = result.Terminated[:limit]
+		}
+		if len(result.MailboxGrowth) > limit {
+			result.MailboxGrowth = result.MailboxGrowth[:limit]
+		}
+		if len(result.MessageThroughput) > limit {
+			result.MessageThroughput = result.MessageThroughput[:limit]
+		}
+	}
+
+	// applications
+	for name, info := range after.Apps {
+		prevState := "unloaded"
+		if prev, ok := before.Apps[name]; ok {
+			prevState = prev.State.String()
+		}
+		if prevState != info.State.String() {
+			result.AppsChanged = append(result.AppsChanged, diffApp{
+				Name:   name,
+				Before: prevState,
+				After:  info.State.String(),
+			})
+		}
+	}
+	for name, prev := range before.Apps {
+		if _, ok := after.Apps[name]; ok == false {
+			result.AppsChanged = append(result.AppsChanged, diffApp{
+				Name:   name,
+				Before: prev.State.String(),
+				After:  "unloaded",
+			})
+		}
+	}
+	sort.Slice(result.AppsChanged, func(i, j int) bool {
+		return result.AppsChanged[i].Name < result.AppsChanged[j].Name
+	})
+
+	// events
+	for event := range after.Events {
+		if _, ok := before.Events[event]; ok == false {
+			result.EventsRegistered = append(result.EventsRegistered, fmt.Sprintf("%s@%s", event.Name, event.Node))
+		}
+	}
+	for event := range before.Events {
+		if _, ok := after.Events[event]; ok == false {
+			result.EventsUnregistered = append(result.EventsUnregistered, fmt.Sprintf("%s@%s", event.Name, event.Node))
+		}
+	}
+	sort.Strings(result.EventsRegistered)
+	sort.Strings(result.EventsUnregistered)
+
+	// connections. Reconnected in between if the connection is younger than
+	// the previous one would be by now (its uptime plus the elapsed time).
+	reconnected := func(prev, info gen.RemoteNodeInfo) bool {
+		expected := prev.ConnectionUptime + int64(elapsed.Seconds()) - connectionUptimeTolerance
+		return info.ConnectionUptime < expected
+	}
+	for peer, info := range after.Connections {
+		prev, ok := before.Connections[peer]
+		if ok == false || reconnected(prev, info) {
+			result.ConnectionsUp = append(result.ConnectionsUp, diffConnection{
+				Node:   peer,
+				Uptime: info.ConnectionUptime,
+			})
+		}
+	}
+	for peer, prev := range before.Connections {
+		info, ok := after.Connections[peer]
+		if ok == false || reconnected(prev, info) {
+			result.ConnectionsDown = append(result.ConnectionsDown, diffConnection{
+				Node:   peer,
+				Uptime: prev.ConnectionUptime,
+			})
+		}
+	}
+	sort.Slice(result.ConnectionsUp, func(i, j int) bool {
+		return result.ConnectionsUp[i].Node < result.ConnectionsUp[j].Node
+	})
+	sort.Slice(result.ConnectionsDown, func(i, j int) bool {
+		return result.ConnectionsDown[i].Node < result.ConnectionsDown[j].Node
+	})
+
+	// loggers
+	for name := range after.Loggers {
+		if _, ok := before.Loggers[name]; ok == false {
+			result.LoggersAdded = append(result.LoggersAdded, name)
+		}
+	}
+	for name := range before.Loggers {
+		if _, ok := after.Loggers[name]; ok == false {
+			result.LoggersRemoved = append(result.LoggersRemoved, name)
+		}
+	}
+	sort.Strings(result.LoggersAdded)
+	sort.Strings(result.LoggersRemoved)
+
+	// cron jobs
+	for name, job := range after.CronJobs {
+		prev, ok := before.CronJobs[name]
+		switch {
+		case ok == false:
+			result.CronJobsChanged = append(result.CronJobsChanged, diffCronJob{Name: name, Change: "added"})
+		case prev.Disabled != job.Disabled && job.Disabled:
+			result.CronJobsChanged = append(result.CronJobsChanged, diffCronJob{Name: name, Change: "disabled"})
+		case prev.Disabled != job.Disabled:
+			result.CronJobsChanged = append(result.CronJobsChanged, diffCronJob{Name: name, Change: "enabled"})
+		case job.LastErr != "" && job.LastErr != prev.LastErr:
+			result.CronJobsChanged = append(result.CronJobsChanged, diffCronJob{Name: name, Change: "failed: " + job.LastErr})
+		case job.LastRun.After(prev.LastRun):
+			result.CronJobsChanged = append(result.CronJobsChanged, diffCronJob{Name: name, Change: "ran at " + job.LastRun.Format(time.RFC3339)})
+		}
+	}
+	for name := range before.CronJobs {
+		if _, ok := after.CronJobs[name]; ok == false {
+			result.CronJobsChanged = append(result.CronJobsChanged, diffCronJob{Name: name, Change: "removed"})
+		}
+	}
+	sort.Slice(result.CronJobsChanged, func(i, j int) bool {
+		return result.CronJobsChanged[i].Name < result.CronJobsChanged[j].Name
+	})
+
+	return result
+}
+
+func newDiffProcess(info gen.ProcessShortInfo) diffProcess {
+	return diffProcess{
+		PID:         info.PID,
+		Name:        info.Name,
+		Behavior:    info.Behavior,
+		Application: info.Application,
+		Mailbox:     info.MessagesMailbox,
+		Uptime:      info.Uptime,
+	}
+}
@@ -76,6 +76,9 @@ func constructMessage(t reflect.Type, data json.RawMessage) (any, error) {
 	return v.Elem().Interface(), nil
 }
 
+// fillStructFromMap and setFieldValue are copied to observer/types.go
+// (typed messages of the dashboard composer). This is the source of truth,
+// keep the copy identical.
 func fillStructFromMap(v reflect.Value, m map[string]any) error {
 	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
@@ -0,0 +1,552 @@
+package observer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"ergo.services/ergo/act"
+	"ergo.services/ergo/gen"
+)
+
+const (
+	alertsEvent gen.Atom = "observer_alerts"
+
+	defaultAlertInterval          = 5 * time.Second
+	defaultMemoryWindow           = 5 * time.Minute
+	defaultWebhookTimeout         = 5 * time.Second
+	defaultAlertNodeExpiry        = time.Hour
+	defaultAlertApplicationExpiry = time.Hour
+
+	// alertsBuffer is the number of recent alerts a new session receives
+	alertsBuffer = 100
+)
+
+// AlertType defines the condition checked by the alert rule
+type AlertType string
+
+const (
+	// AlertMailbox fires for a process with a mailbox depth above Threshold
+	// for longer than For.
+	AlertMailbox AlertType = "mailbox"
+
+	// AlertMemoryGrowth fires when the memory used by the node grew by more than
+	// Threshold percent within For (default 5m).
+	AlertMemoryGrowth AlertType = "memory_growth"
+
+	// AlertConnectionLost fires when the connection to a node (RemoteNode or any)
+	// that was connected is lost. Resolved once the node is connected again, or
+	// forgotten after AlertOptions.NodeExpiry (the node was removed from the cluster).
+	AlertConnectionLost AlertType = "connection_lost"
+
+	// AlertApplicationDown fires when an application (Application or any)
+	// that was running leaves the running state. Resolved once it is running again,
+	// unloaded, or after AlertOptions.ApplicationExpiry (stopped on purpose).
+	AlertApplicationDown AlertType = "application_down"
+
+	// AlertErrorRate fires when the node logs more than Threshold error/panic
+	// messages per minute.
+	AlertErrorRate AlertType = "error_rate"
+)
+
+// AlertRule describes the condition to be evaluated by Observer
+type AlertRule struct {
+	// Name of the rule, must be unique. Default: the type
+	Name string
+	Type AlertType
+
+	// Threshold of the value: mailbox depth, memory growth in percent,
+	// errors per minute.
+	Threshold float64
+
+	// For how long the condition must hold before the alert fires. For AlertMemoryGrowth
+	// it is the window the growth is measured in.
+	For time.Duration
+
+	// Application limits AlertApplicationDown to the given application
+	Application gen.Atom
+
+	// RemoteNode limits AlertConnectionLost to the given node
+	RemoteNode gen.Atom
+}
+
+type AlertOptions struct {
+	// Rules to evaluate. Empty = alerting is disabled
+	Rules []AlertRule
+
+	// Interval of the rules evaluation. Default: 5s
+	Interval time.Duration
+
+	// Webhook URL. Fired and resolved alerts are POSTed there as JSON. Empty = disabled
+	Webhook string
+
+	// WebhookHeaders are added to the webhook request (e.g. Authorization)
+	WebhookHeaders map[string]string
+
+	// WebhookTimeout limits the webhook request. Default: 5s
+	WebhookTimeout time.Duration
+
+	// NodeExpiry is how long a disconnected node is watched by the AlertConnectionLost
+	// rules. After that it is forgotten and its alert is resolved. Default: 1h
+	NodeExpiry time.Duration
+
+	// ApplicationExpiry is how long a stopped application is watched by the
+	// AlertApplicationDown rules. After that it is forgotten and its alert is resolved.
+	// Unloaded applications are forgotten right away. Default: 1h
+	ApplicationExpiry time.Duration
+}
+
+// Alert is sent to the sessions as the "alert" SSE event and POSTed to the webhook
+type Alert struct {
+	Rule    string    `json:"Rule"`
+	Type    AlertType `json:"Type"`
+	State   string    `json:"State"` // "firing" or "resolved"
+	Subject string    `json:"Subject"`
+	Message string    `json:"Message"`
+	Value   float64   `json:"Value"`
+	Node    gen.Atom  `json:"Node"`
+	Since   time.Time `json:"Since"`
+	Time    time.Time `json:"Time"`
+}
+
+const (
+	alertStateFiring   = "firing"
+	alertStateResolved = "resolved"
+)
+
+type messageAlertsTick struct{}
+
+// alertCondition is a rule condition that currently holds
+type alertCondition struct {
+	subject string
+	value   float64
+	message string
+}
+
+type memorySample struct {
+	time time.Time
+	used uint64
+}
+
+func factory_alerts() gen.ProcessBehavior {
+	return &alerts{}
+}
+
+// alerts evaluates the alert rules periodically and publishes fired and resolved
+// alerts with the observer_alerts event. Every session monitors this event.
+type alerts struct {
+	act.Actor
+
+	options AlertOptions
+	token   gen.Ref
+	client  *http.Client
+
+	pending map[string]time.Time // condition key → since when it holds
+	active  map[string]Alert     // condition key → fired alert
+
+	memory      []memorySample
+	seenNodes   map[gen.Atom]time.Time // node → last time it was connected
+	seenApps    map[gen.Atom]time.Time // application → last time it was running
+	errors      int
+	errorsSince time.Time
+	loggerName  string
+}
+
+func (a *alerts) Init(args ...any) error {
+	a.Log().SetLogger("default")
+	a.options = args[0].(Options).Alerts
+	if a.options.Interval <= 0 {
+		a.options.Interval = defaultAlertInterval
+	}
+	if a.options.WebhookTimeout <= 0 {
+		a.options.WebhookTimeout = defaultWebhookTimeout
+	}
+	if a.options.NodeExpiry <= 0 {
+		a.options.NodeExpiry = defaultAlertNodeExpiry
+	}
+	if a.options.ApplicationExpiry <= 0 {
+		a.options.ApplicationExpiry = defaultAlertApplicationExpiry
+	}
+	// the defaults are set below, the caller's slice stays as is
+	a.options.Rules = append([]AlertRule(nil), a.options.Rules...)
+
+	names := make(map[string]bool)
+	for i, rule := range a.options.Rules {
+		if rule.Name == "" {
+			rule.Name = string(rule.Type)
+			a.options.Rules[i].Name = rule.Name
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate alert rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		switch rule.Type {
+		case AlertMailbox, AlertMemoryGrowth, AlertConnectionLost, AlertApplicationDown:
+		case AlertErrorRate:
+			if a.loggerName != "" {
+				continue
+			}
+			// count error messages of the node
+			a.loggerName = string(alertsEvent)
+			if err := a.Node().LoggerAddPID(a.PID(), a.loggerName, gen.LogLevelError, gen.LogLevelPanic); err != nil {
+				return fmt.Errorf("cannot register logger: %w", err)
+			}
+		default:
+			return fmt.Errorf("alert rule %q: unknown type %q", rule.Name, rule.Type)
+		}
+	}
+
+	token, err := a.RegisterEvent(alertsEvent, gen.EventOptions{Buffer: alertsBuffer})
+	if err != nil {
+		return err
+	}
+	a.token = token
+
+	a.client = &http.Client{Timeout: a.options.WebhookTimeout}
+	a.pending = make(map[string]time.Time)
+	a.active = make(map[string]Alert)
+	a.seenNodes = make(map[gen.Atom]time.Time)
+	a.seenApps = make(map[gen.Atom]time.Time)
+	a.errorsSince = time.Now()
+
+	a.Send(a.PID(), messageAlertsTick{})
+	a.Log().Info("alerting started with %d rules", len(a.options.Rules))
+	return nil
+}
+
+func (a *alerts) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	switch request.(type) {
+	case requestAlertList:
+		return a.activeAlerts(), nil
+	}
+	return nil, gen.ErrUnsupported
+}
+
+func (a *alerts) HandleMessage(from gen.PID, message any) error {
+	switch message.(type) {
+	case messageAlertsTick:
+		a.evaluate()
+		a.SendAfter(a.PID(), messageAlertsTick{}, a.options.Interval)
+	default:
+		a.Log().Warning("unknown message from %s: %#v", from, message)
+	}
+	return nil
+}
+
+// HandleLog counts error messages for the AlertErrorRate rules
+func (a *alerts) HandleLog(message gen.MessageLog) error {
+	a.errors++
+	return nil
+}
+
+func (a *alerts) Terminate(reason error) {
+	if a.loggerName != "" {
+		a.Node().LoggerDeletePID(a.PID())
+	}
+	a.Log().Info("alerting terminated: %s", reason)
+}
+
+// evaluate checks every rule. A condition that holds for longer than For fires
+// the alert, the alert is resolved once the condition is gone.
+func (a *alerts) evaluate() {
+	now := time.Now()
+	a.sampleMemory(now)
+	connected := a.connectedNodes()
+	running := a.runningApplications()
+
+	errorRate := 0.0
+	if elapsed := now.Sub(a.errorsSince); elapsed >= time.Second {
+		errorRate = float64(a.errors) / elapsed.Minutes()
+		a.errors = 0
+		a.errorsSince = now
+	}
+
+	holding := make(map[string]bool)
+	for _, rule := range a.options.Rules {
+		var conditions []alertCondition
+		switch rule.Type {
+		case AlertMailbox:
+			conditions = a.checkMailbox(rule)
+		case AlertMemoryGrowth:
+			conditions = a.checkMemory(rule, now)
+		case AlertConnectionLost:
+			conditions = a.checkConnections(rule, connected)
+		case AlertApplicationDown:
+			conditions = a.checkApplications(rule, running)
+		case AlertErrorRate:
+			if errorRate > rule.Threshold {
+				conditions = append(conditions, alertCondition{
+					subject: string(a.Node().Name()),
+					value:   errorRate,
+					message: fmt.Sprintf("%.1f errors per minute (threshold %g)", errorRate, rule.Threshold),
+				})
+			}
+		}
+
+		for _, c := range conditions {
+			key := rule.Name + "/" + c.subject
+			holding[key] = true
+			since, exist := a.pending[key]
+			if exist == false {
+				since = now
+				a.pending[key] = since
+			}
+			if _, fired := a.active[key]; fired {
+				continue
+			}
+			// the memory rule measures within For, it fires right away
+			if rule.Type != AlertMemoryGrowth && now.Sub(since) < rule.For {
+				continue
+			}
+			alert := Alert{
+				Rule:    rule.Name,
+				Type:    rule.Type,
+				State:   alertStateFiring,
+				Subject: c.subject,
+				Message: c.message,
+				Value:   c.value,
+				Node:    a.Node().Name(),
+				Since:   since,
+				Time:    now,
+			}
+			a.active[key] = alert
+			a.publish(alert)
+		}
+	}
+
+	for key := range a.pending {
+		if holding[key] {
+			continue
+		}
+		delete(a.pending, key)
+		alert, fired := a.active[key]
+		if fired == false {
+			continue
+		}
+		delete(a.active, key)
+		alert.State = alertStateResolved
+		alert.Time = now
+		a.publish(alert)
+	}
+}
+
+func (a *alerts) checkMailbox(rule AlertRule) []alertCondition {
+	var conditions []alertCondition
+	a.Node().ProcessRangeShortInfo(func(info gen.ProcessShortInfo) bool {
+		if float64(info.MessagesMailbox) <= rule.Threshold {
+			return true
+		}
+		subject := info.PID.String()
+		if info.Name != "" {
+			subject = fmt.Sprintf("%s (%s)", info.PID, info.Name)
+		}
+		conditions = append(conditions, alertCondition{
+			subject: subject,
+			value:   float64(info.MessagesMailbox),
+			message: fmt.Sprintf("mailbox depth %d (threshold %g)", info.MessagesMailbox, rule.Threshold),
+		})
+		return true
+	})
+	return conditions
+}
+
+func (a *alerts) sampleMemory(now time.Time) {
+	info, err := a.Node().Info()
+	if err != nil {
+		return
+	}
+	a.memory = append(a.memory, memorySample{time: now, used: info.MemoryUsed})
+
+	// keep samples of the longest window
+	window := defaultMemoryWindow
+	for _, rule := range a.options.Rules {
+		if rule.Type == AlertMemoryGrowth && rule.For > window {
+			window = rule.For
+		}
+	}
+	i := 0
+	for i < len(a.memory)-1 && now.Sub(a.memory[i].time) > window {
+		i++
+	}
+	a.memory = a.memory[i:]
+}
+
+func (a *alerts) checkMemory(rule AlertRule, now time.Time) []alertCondition {
+	window := rule.For
+	if window <= 0 {
+		window = defaultMemoryWindow
+	}
+	if len(a.memory) < 2 {
+		return nil
+	}
+	// the oldest sample within the window
+	first := a.memory[len(a.memory)-1]
+	for _, sample := range a.memory {
+		if now.Sub(sample.time) <= window {
+			first = sample
+			break
+		}
+	}
+	last := a.memory[len(a.memory)-1]
+	if first.used == 0 || last.used <= first.used {
+		return nil
+	}
+	growth := float64(last.used-first.used) * 100 / float64(first.used)
+	if growth <= rule.Threshold {
+		return nil
+	}
+	return []alertCondition{{
+		subject: string(a.Node().Name()),
+		value:   growth,
+		message: fmt.Sprintf("memory used grew by %.1f%% within %s (%d → %d bytes)",
+			growth, window, first.used, last.used),
+	}}
+}
+
+func (a *alerts) connectedNodes() map[gen.Atom]bool {
+	now := time.Now()
+	connected := make(map[gen.Atom]bool)
+	for _, node := range a.Node().Network().Nodes() {
+		connected[node] = true
+		a.seenNodes[node] = now
+	}
+	// the node is gone for good, its connection_lost alerts get resolved
+	for node, seen := range a.seenNodes {
+		if now.Sub(seen) > a.options.NodeExpiry {
+			delete(a.seenNodes, node)
+		}
+	}
+	return connected
+}
+
+func (a *alerts) checkConnections(rule AlertRule, connected map[gen.Atom]bool) []alertCondition {
+	var conditions []alertCondition
+	for node := range a.seenNodes {
+		if rule.RemoteNode != "" && node != rule.RemoteNode {
+			continue
+		}
+		if connected[node] {
+			continue
+		}
+		conditions = append(conditions, alertCondition{
+			subject: string(node),
+			message: fmt.Sprintf("connection to %s is lost", node),
+		})
+	}
+	return conditions
+}
+
+func (a *alerts) runningApplications() map[gen.Atom]gen.ApplicationState {
+	states := make(map[gen.Atom]gen.ApplicationState)
+	for _, name := range a.Node().Applications() {
+		info, err := a.Node().ApplicationInfo(name)
+		if err != nil {
+			continue
+		}
+		states[name] = info.State
+	}
+	a.trackApplications(states, time.Now())
+	return states
+}
+
+// trackApplications keeps the applications seen running. Unloaded ones and those
+// stopped for longer than ApplicationExpiry are forgotten (removed on purpose),
+// so their application_down alerts get resolved.
+func (a *alerts) trackApplications(states map[gen.Atom]gen.ApplicationState, now time.Time) {
+	for name, state := range states {
+		if state == gen.ApplicationStateRunning {
+			a.seenApps[name] = now
+		}
+	}
+	for name, seen := range a.seenApps {
+		if _, loaded := states[name]; loaded == false || now.Sub(seen) > a.options.ApplicationExpiry {
+			delete(a.seenApps, name)
+		}
+	}
+}
+
+func (a *alerts) checkApplications(rule AlertRule, states map[gen.Atom]gen.ApplicationState) []alertCondition {
+	var conditions []alertCondition
+	for name := range a.seenApps {
+		if rule.Application != "" && name != rule.Application {
+			continue
+		}
+		state := states[name]
+		if state == gen.ApplicationStateRunning {
+			continue
+		}
+		conditions = append(conditions, alertCondition{
+			subject: string(name),
+			message: fmt.Sprintf("application %s is %s", name, state),
+		})
+	}
+	return conditions
+}
+
+// publish sends the alert to the sessions and to the webhook
+func (a *alerts) publish(alert Alert) {
+	if alert.State == alertStateFiring {
+		a.Log().Warning("alert %s [%s]: %s", alert.Rule, alert.Subject, alert.Message)
+	} else {
+		a.Log().Info("alert %s [%s] resolved", alert.Rule, alert.Subject)
+	}
+
+	if err := a.SendEvent(alertsEvent, a.token, alert); err != nil {
+		a.Log().Error("unable to send alert event: %s", err)
+	}
+
+	if a.options.Webhook == "" {
+		return
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		a.Log().Error("unable to marshal alert: %s", err)
+		return
+	}
+	go a.postWebhook(body)
+}
+
+// postWebhook runs in a goroutine, so the slow receiver doesn't delay evaluation.
+// Failures are logged as warnings, they must not feed the error rate rule.
+func (a *alerts) postWebhook(body []byte) {
+	if err := postAlert(a.client, a.options.Webhook, a.options.WebhookHeaders, body); err != nil {
+		a.Node().Log().Warning("observer alerts: webhook: %s", err)
+	}
+}
+
+// postAlert POSTs the JSON encoded alert to the webhook
+func postAlert(client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// activeAlerts returns the alerts currently firing, oldest first
+func (a *alerts) activeAlerts() []Alert {
+	list := make([]Alert, 0, len(a.active))
+	for _, alert := range a.active {
+		list = append(list, alert)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Since.Before(list[j].Since)
+	})
+	return list
+}
@@ -0,0 +1,134 @@
+package observer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ergo.services/ergo/gen"
+)
+
+func TestPostAlert(t *testing.T) {
+	received := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected application/json, got %q", ct)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("expected the webhook header, got %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var alert Alert
+		if err := json.Unmarshal(body, &alert); err != nil {
+			t.Errorf("invalid body: %s", err)
+		}
+		received <- alert
+	}))
+	defer server.Close()
+
+	alert := Alert{
+		Rule:    "mailbox",
+		Type:    AlertMailbox,
+		State:   alertStateFiring,
+		Subject: "<ABC.0.1000>",
+		Value:   1500,
+	}
+	body, _ := json.Marshal(alert)
+	headers := map[string]string{"Authorization": "Bearer secret"}
+	if err := postAlert(server.Client(), server.URL, headers, body); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-received
+	if got.Rule != alert.Rule || got.State != alert.State || got.Subject != alert.Subject || got.Value != alert.Value {
+		t.Fatalf("expected %#v, got %#v", alert, got)
+	}
+}
+
+func TestPostAlertStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := postAlert(server.Client(), server.URL, nil, []byte("{}")); err == nil {
+		t.Fatal("expected error on the 500 response")
+	}
+}
+
+func TestCheckApplications(t *testing.T) {
+	a := &alerts{
+		options:  AlertOptions{ApplicationExpiry: time.Hour},
+		seenApps: make(map[gen.Atom]time.Time),
+	}
+	rule := AlertRule{Name: "apps", Type: AlertApplicationDown}
+	now := time.Now()
+
+	// never seen running, no alert
+	states := map[gen.Atom]gen.ApplicationState{"app": gen.ApplicationStateLoaded}
+	a.trackApplications(states, now)
+	if c := a.checkApplications(rule, states); len(c) != 0 {
+		t.Fatalf("expected no conditions, got %v", c)
+	}
+
+	states["app"] = gen.ApplicationStateRunning
+	a.trackApplications(states, now)
+	if c := a.checkApplications(rule, states); len(c) != 0 {
+		t.Fatalf("expected no conditions, got %v", c)
+	}
+
+	// stopped
+	states["app"] = gen.ApplicationStateLoaded
+	a.trackApplications(states, now.Add(time.Minute))
+	c := a.checkApplications(rule, states)
+	if len(c) != 1 || c[0].subject != "app" {
+		t.Fatalf("expected the condition for app, got %v", c)
+	}
+
+	// the rule limited to another application
+	other := AlertRule{Name: "other", Type: AlertApplicationDown, Application: "other"}
+	if c := a.checkApplications(other, states); len(c) != 0 {
+		t.Fatalf("expected no conditions, got %v", c)
+	}
+
+	// stopped for longer than ApplicationExpiry
+	a.trackApplications(states, now.Add(2*time.Hour))
+	if c := a.checkApplications(rule, states); len(c) != 0 {
+		t.Fatalf("expected the expired application to be forgotten, got %v", c)
+	}
+
+	// unloaded
+	states["app"] = gen.ApplicationStateRunning
+	a.trackApplications(states, now)
+	delete(states, "app")
+	a.trackApplications(states, now)
+	if c := a.checkApplications(rule, states); len(c) != 0 {
+		t.Fatalf("expected the unloaded application to be forgotten, got %v", c)
+	}
+}
+
+func TestCheckConnections(t *testing.T) {
+	a := &alerts{
+		seenNodes: map[gen.Atom]time.Time{
+			"a@localhost": time.Now(),
+			"b@localhost": time.Now(),
+		},
+	}
+	connected := map[gen.Atom]bool{"a@localhost": true}
+
+	c := a.checkConnections(AlertRule{Type: AlertConnectionLost}, connected)
+	if len(c) != 1 || c[0].subject != "b@localhost" {
+		t.Fatalf("expected the condition for b@localhost, got %v", c)
+	}
+
+	rule := AlertRule{Type: AlertConnectionLost, RemoteNode: "a@localhost"}
+	if c := a.checkConnections(rule, connected); len(c) != 0 {
+		t.Fatalf("expected no conditions, got %v", c)
+	}
+}
@@ -10,6 +10,14 @@ const (
 	mgrName  gen.Atom = "observer_mgr"
 	webName  gen.Atom = "observer_web"
 	poolName gen.Atom = "observer_post_pool"
+	restName gen.Atom = "observer_rest"
+
+	historyName gen.Atom = "observer_history"
+	clusterName gen.Atom = "observer_cluster"
+	cronName    gen.Atom = "observer_cron"
+	logsName    gen.Atom = "observer_logs"
+
+	alertsName gen.Atom = "observer_alerts"
 )
 
 func CreateApp(options Options) gen.ApplicationBehavior {
@@ -19,6 +27,21 @@ func CreateApp(options Options) gen.ApplicationBehavior {
 	if options.PoolSize < 1 {
 		options.PoolSize = defaultPoolSize
 	}
+	if options.SessionGracePeriod == 0 {
+		options.SessionGracePeriod = defaultSessionGracePeriod
+	}
+	if options.LogBacklog == 0 {
+		options.LogBacklog = defaultLogBacklog
+	}
+	if options.RecordMaxSize < 1 {
+		options.RecordMaxSize = defaultRecordMaxSize
+	}
+	if options.RecordMaxFiles < 1 {
+		options.RecordMaxFiles = defaultRecordMaxFiles
+	}
+	if options.History.Duration == 0 {
+		options.History.Duration = defaultHistoryDuration
+	}
 	return &app{options: options}
 }
 
@@ -40,17 +63,56 @@ func (a *app) Load(node gen.Node, args ...any) (gen.ApplicationSpec, error) {
 			{
 				Name:    mgrName,
 				Factory: factory_mgr,
+				Args:    []any{a.options},
 			},
 			{
 				Name:    poolName,
 				Factory: factory_post_pool,
+				Args:    []any{a.options},
 			},
 			{
-				Name:    webName,
-				Factory: factory_web,
+				Name:    clusterName,
+				Factory: factory_cluster,
+				Args:    []any{a.options},
+			},
+			{
+				Name:    cronName,
+				Factory: factory_cron,
+				Args:    []any{a.options},
+			},
+			{
+				Name:    restName,
+				Factory: factory_rest,
+				Args:    []any{a.options},
 			},
 		},
 	}
+	if a.options.LogBacklog > 0 {
+		spec.Group = append(spec.Group, gen.ApplicationMemberSpec{
+			Name:    logsName,
+			Factory: factory_logs,
+			Args:    []any{a.options},
+		})
+	}
+	if a.options.History.Duration > 0 {
+		spec.Group = append(spec.Group, gen.ApplicationMemberSpec{
+			Name:    historyName,
+			Factory: factory_history,
+			Args:    []any{a.options},
+		})
+	}
+	if len(a.options.Alerts.Rules) > 0 {
+		spec.Group = append(spec.Group, gen.ApplicationMemberSpec{
+			Name:    alertsName,
+			Factory: factory_alerts,
+			Args:    []any{a.options},
+		})
+	}
+	spec.Group = append(spec.Group, gen.ApplicationMemberSpec{
+		Name:    webName,
+		Factory: factory_web,
+		Args:    []any{a.options},
+	})
 	spec.Depends = gen.ApplicationDepends{
 		Applications: []gen.Atom{system.Name},
 	}
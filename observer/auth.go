@@ -0,0 +1,116 @@
+package observer
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AuthOptions configures authentication of the Observer HTTP endpoints
+// (/sse, /api/ and static assets). All configured methods are accepted:
+// a request passes if it matches any of them. Empty = no auth.
+type AuthOptions struct {
+	// Users for HTTP Basic authentication: username -> password.
+	// The username becomes the identity of the request.
+	Users map[string]string
+
+	// Tokens for Bearer authentication: token -> identity.
+	// EventSource in browsers can not set headers, so the token is also
+	// accepted in the "token" query parameter.
+	Tokens map[string]string
+
+	// Middleware wraps all Observer handlers. Use it to plug in an external
+	// authentication scheme (SSO proxy headers, sessions, etc). It runs before
+	// the Users/Tokens check. To attribute actions, pass the request
+	// returned by WithIdentity to the next handler. A request that already
+	// has an identity skips the Users/Tokens check.
+	Middleware func(next http.Handler) http.Handler
+
+	// Realm for the Basic authentication challenge. Default: "observer"
+	Realm string
+}
+
+func (a AuthOptions) enabled() bool {
+	return len(a.Users) > 0 || len(a.Tokens) > 0 || a.Middleware != nil
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a shallow copy of the request carrying the authenticated identity.
+func WithIdentity(request *http.Request, identity string) *http.Request {
+	ctx := context.WithValue(request.Context(), identityKey{}, identity)
+	return request.WithContext(ctx)
+}
+
+// Identity returns the authenticated identity of the request. Empty if not authenticated.
+func Identity(request *http.Request) string {
+	identity, _ := request.Context().Value(identityKey{}).(string)
+	return identity
+}
+
+// authHandler wraps the handler with the configured authentication methods.
+func authHandler(options AuthOptions, next http.Handler) http.Handler {
+	if options.enabled() == false {
+		return next
+	}
+
+	realm := options.Realm
+	if realm == "" {
+		realm = "observer"
+	}
+
+	var handler http.Handler = next
+	if len(options.Users) > 0 || len(options.Tokens) > 0 {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if Identity(r) != "" {
+				// authenticated by middleware
+				next.ServeHTTP(w, r)
+				return
+			}
+			if identity, ok := authenticate(options, r); ok {
+				next.ServeHTTP(w, WithIdentity(r, identity))
+				return
+			}
+			if len(options.Users) > 0 {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+			} else {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+
+	if options.Middleware != nil {
+		handler = options.Middleware(handler)
+	}
+	return handler
+}
+
+func authenticate(options AuthOptions, r *http.Request) (string, bool) {
+	if user, password, ok := r.BasicAuth(); ok {
+		if expected, exist := options.Users[user]; exist && secureEqual(expected, password) {
+			return user, true
+		}
+		return "", false
+	}
+
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if token == "" {
+		return "", false
+	}
+	for t, identity := range options.Tokens {
+		if secureEqual(t, token) {
+			return identity, true
+		}
+	}
+	return "", false
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,388 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ergo.services/ergo/act"
+	"ergo.services/ergo/app/system/inspect"
+	"ergo.services/ergo/gen"
+)
+
+const (
+	clusterEvent gen.Atom = "observer_cluster"
+
+	defaultClusterInterval         = 5 * time.Second
+	defaultClusterMailboxThreshold = 100
+
+	// clusterHotspots limits the number of processes with the deepest mailbox per node
+	clusterHotspots = 5
+)
+
+// clusterOverview is sent as the "cluster_overview" SSE event
+type clusterOverview struct {
+	Nodes   []*clusterNode `json:"Nodes"`
+	Updated time.Time      `json:"Updated"`
+}
+
+// clusterNode is a row of the cluster overview. Stats are taken from the latest
+// events of the inspectors on that node.
+type clusterNode struct {
+	Name      gen.Atom `json:"Name"`
+	CRC32     string   `json:"CRC32"`
+	Reachable bool     `json:"Reachable"`
+	Error     string   `json:"Error,omitempty"`
+
+	clusterNodeStats
+
+	Applications    json.RawMessage  `json:"Applications,omitempty"` // as in the application_list event
+	MailboxHotspots []clusterHotspot `json:"MailboxHotspots"`
+
+	Updated time.Time `json:"Updated"`
+}
+
+// clusterNodeStats are the fields of gen.NodeInfo shown in the overview
+type clusterNodeStats struct {
+	Uptime              int64  `json:"Uptime"`
+	ProcessesTotal      int64  `json:"ProcessesTotal"`
+	ProcessesRunning    int64  `json:"ProcessesRunning"`
+	MemoryUsed          uint64 `json:"MemoryUsed"`
+	MemoryAlloc         uint64 `json:"MemoryAlloc"`
+	ApplicationsTotal   int64  `json:"ApplicationsTotal"`
+	ApplicationsRunning int64  `json:"ApplicationsRunning"`
+	SendErrorsRemote    uint64 `json:"SendErrorsRemote"`
+	CallErrorsRemote    uint64 `json:"CallErrorsRemote"`
+}
+
+type clusterHotspot struct {
+	PID             json.RawMessage `json:"PID"`
+	Name            string          `json:"Name"`
+	MessagesMailbox uint64          `json:"MessagesMailbox"`
+}
+
+type messageClusterTick struct{}
+
+// messageClusterWatch sent by cluster to the post pool: a worker makes the inspect
+// calls on the node and sends messageClusterWatched back, so a slow or unreachable
+// node doesn't hold the ticks.
+type messageClusterWatch struct {
+	Node      gen.Atom
+	Connect   bool // see Options.ClusterConnect
+	Threshold uint64
+}
+
+type messageClusterWatched struct {
+	Node   gen.Atom
+	Events []gen.Event
+	Error  string
+}
+
+func factory_cluster() gen.ProcessBehavior {
+	return &cluster{}
+}
+
+// cluster builds the overview of all known nodes (connected and discovered by
+// the registrar). It watches the inspectors of every node only while there are
+// sessions subscribed to the cluster_overview, and publishes the overview
+// with the observer_cluster event every interval.
+// The inspect calls are made by the post pool workers (see messageClusterWatch).
+type cluster struct {
+	act.Actor
+
+	token     gen.Ref
+	interval  time.Duration
+	threshold uint64
+	connect   bool
+
+	active  bool // the event has subscribers
+	ticking bool
+
+	nodes    map[gen.Atom]*clusterNode
+	watched  map[gen.Atom][]gen.Event // node → inspect events monitored on that node
+	events   map[string]gen.Atom      // event key → node
+	watching map[gen.Atom]bool        // nodes awaiting messageClusterWatched
+}
+
+func (c *cluster) Init(args ...any) error {
+	c.Log().SetLogger("default")
+	options := args[0].(Options)
+	c.interval = options.ClusterInterval
+	if c.interval <= 0 {
+		c.interval = defaultClusterInterval
+	}
+	c.threshold = options.ClusterMailboxThreshold
+	if c.threshold == 0 {
+		c.threshold = defaultClusterMailboxThreshold
+	}
+	c.connect = options.ClusterConnect
+	c.nodes = make(map[gen.Atom]*clusterNode)
+	c.watched = make(map[gen.Atom][]gen.Event)
+	c.events = make(map[string]gen.Atom)
+	c.watching = make(map[gen.Atom]bool)
+
+	// Notify: watch the nodes only while sessions are subscribed.
+	// Buffer: a new subscriber gets the latest overview right away.
+	token, err := c.RegisterEvent(clusterEvent, gen.EventOptions{Notify: true, Buffer: 1})
+	if err != nil {
+		return err
+	}
+	c.token = token
+	return nil
+}
+
+func (c *cluster) HandleMessage(from gen.PID, message any) error {
+	switch m := message.(type) {
+	case gen.MessageEventStart:
+		c.active = true
+		if c.ticking == false {
+			c.ticking = true
+			c.Send(c.PID(), messageClusterTick{})
+		}
+
+	case gen.MessageEventStop:
+		c.active = false
+		for node := range c.watched {
+			c.unwatch(node)
+		}
+		c.nodes = make(map[gen.Atom]*clusterNode)
+
+	case messageClusterTick:
+		if c.active == false {
+			c.ticking = false
+			return nil
+		}
+		c.refresh()
+		c.SendAfter(c.PID(), messageClusterTick{}, c.interval)
+
+	case messageClusterWatched:
+		delete(c.watching, m.Node)
+		if c.active == false {
+			return nil
+		}
+		c.watch(m)
+
+	case gen.MessageDownEvent:
+		node, exist := c.events[m.Event.String()]
+		if exist == false {
+			return nil
+		}
+		// the node is gone or its inspector terminated, retry on the next tick
+		c.unwatch(node)
+		if row, exist := c.nodes[node]; exist {
+			row.Reachable = false
+			row.Error = fmt.Sprintf("inspector event %s terminated: %s", m.Event.Name, m.Reason)
+		}
+
+	default:
+		c.Log().Warning("unknown message from %s: %#v", from, message)
+	}
+	return nil
+}
+
+func (c *cluster) HandleEvent(message gen.MessageEvent) error {
+	node, exist := c.events[message.Event.String()]
+	if exist == false {
+		return nil
+	}
+	c.apply(node, message.Event.Name, message.Message)
+	return nil
+}
+
+func (c *cluster) Terminate(reason error) {
+	c.Log().Info("cluster overview terminated: %s", reason)
+}
+
+// refresh requests watching the nodes that appeared since the previous tick
+// and publishes the overview
+func (c *cluster) refresh() {
+	for _, node := range c.knownNodes() {
+		if _, exist := c.watched[node]; exist || c.watching[node] {
+			continue
+		}
+		row := c.row(node)
+		if c.connect == false && node != c.Node().Name() {
+			if _, err := c.Node().Network().Node(node); err != nil {
+				row.Reachable = false
+				row.Error = "not connected"
+				continue
+			}
+		}
+		request := messageClusterWatch{Node: node, Connect: c.connect, Threshold: c.threshold}
+		if err := c.Send(poolName, request); err != nil {
+			row.Reachable = false
+			row.Error = err.Error()
+			continue
+		}
+		c.watching[node] = true
+	}
+
+	overview := clusterOverview{Updated: time.Now()}
+	for _, row := range c.nodes {
+		overview.Nodes = append(overview.Nodes, row)
+	}
+	sort.Slice(overview.Nodes, func(i, j int) bool {
+		return overview.Nodes[i].Name < overview.Nodes[j].Name
+	})
+	if err := c.SendEvent(clusterEvent, c.token, overview); err != nil {
+		c.Log().Error("unable to send cluster overview: %s", err)
+	}
+}
+
+// knownNodes returns this node, the connected nodes and the nodes discovered by the registrar
+func (c *cluster) knownNodes() []gen.Atom {
+	nodes := []gen.Atom{c.Node().Name()}
+	seen := map[gen.Atom]bool{c.Node().Name(): true}
+	add := func(list []gen.Atom) {
+		for _, node := range list {
+			if seen[node] {
+				continue
+			}
+			seen[node] = true
+			nodes = append(nodes, node)
+		}
+	}
+	add(c.Node().Network().Nodes())
+	if registrar, err := c.Node().Network().Registrar(); err == nil {
+		if list, err := registrar.Nodes(); err == nil {
+			add(list)
+		}
+	}
+	return nodes
+}
+
+func (c *cluster) row(node gen.Atom) *clusterNode {
+	row, exist := c.nodes[node]
+	if exist == false {
+		row = &clusterNode{Name: node, CRC32: node.CRC32()}
+		c.nodes[node] = row
+	}
+	return row
+}
+
+// watch monitors the inspect events started by the post pool worker on the node
+func (c *cluster) watch(m messageClusterWatched) {
+	row := c.row(m.Node)
+	if m.Error != "" {
+		row.Reachable = false
+		row.Error = m.Error
+		return
+	}
+	for _, event := range m.Events {
+		buffered, err := c.MonitorEvent(event)
+		if err != nil {
+			c.unwatch(m.Node)
+			row.Reachable = false
+			row.Error = fmt.Sprintf("monitor: %s", err)
+			return
+		}
+		c.watched[m.Node] = append(c.watched[m.Node], event)
+		c.events[event.String()] = m.Node
+		for _, b := range buffered {
+			c.apply(m.Node, event.Name, b.Message)
+		}
+	}
+	row.Reachable = true
+	row.Error = ""
+}
+
+// handleClusterWatch starts the inspectors of the node info, application list and
+// mailbox hotspots on the node and sends their events to the cluster process
+func (w *postWorker) handleClusterWatch(m messageClusterWatch) {
+	watched := messageClusterWatched{Node: m.Node}
+	defer func() {
+		if err := w.Send(clusterName, watched); err != nil {
+			w.Log().Error("unable to send cluster watch result: %s", err)
+		}
+	}()
+
+	if m.Connect {
+		if err := connectNode(w.Node(), m.Node, nil); err != nil {
+			watched.Error = fmt.Sprintf("connect: %s", err)
+			return
+		}
+	} else if m.Node != w.Node().Name() {
+		// the inspect call would establish the connection otherwise
+		if _, err := w.Node().Network().Node(m.Node); err != nil {
+			watched.Error = "not connected"
+			return
+		}
+	}
+
+	requests := []any{
+		inspect.RequestInspectNode{},
+		inspect.RequestInspectApplicationList{},
+		inspect.RequestInspectProcessRange{Limit: 100, MinMailbox: m.Threshold},
+	}
+	inspectPID := gen.ProcessID{Name: inspect.Name, Node: m.Node}
+	for _, request := range requests {
+		result, err := w.CallWithTimeout(inspectPID, request, defaultCallTimeout)
+		if err != nil {
+			watched.Error = fmt.Sprintf("inspect call: %s", err)
+			return
+		}
+		event, err := extractEvent(result)
+		if err != nil {
+			watched.Error = fmt.Sprintf("inspect response: %s", err)
+			return
+		}
+		watched.Events = append(watched.Events, event)
+	}
+}
+
+func (c *cluster) unwatch(node gen.Atom) {
+	for _, event := range c.watched[node] {
+		c.DemonitorEvent(event)
+		delete(c.events, event.String())
+	}
+	delete(c.watched, node)
+}
+
+// apply updates the node row with the inspector data. The data is decoded
+// from its JSON form, the same the frontend gets for the single node views.
+func (c *cluster) apply(node gen.Atom, name gen.Atom, message any) {
+	row, exist := c.nodes[node]
+	if exist == false {
+		return
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	row.Updated = time.Now()
+
+	switch n := string(name); {
+	case n == "inspect_node":
+		var m struct {
+			Info clusterNodeStats `json:"Info"`
+		}
+		if err := json.Unmarshal(data, &m); err == nil {
+			row.clusterNodeStats = m.Info
+		}
+
+	case n == "inspect_application_list":
+		var m struct {
+			Applications json.RawMessage `json:"Applications"`
+		}
+		if err := json.Unmarshal(data, &m); err == nil {
+			row.Applications = m.Applications
+		}
+
+	case strings.HasPrefix(n, "inspect_process_range"):
+		var m struct {
+			Processes []clusterHotspot `json:"Processes"`
+		}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return
+		}
+		sort.Slice(m.Processes, func(i, j int) bool {
+			return m.Processes[i].MessagesMailbox > m.Processes[j].MessagesMailbox
+		})
+		if len(m.Processes) > clusterHotspots {
+			m.Processes = m.Processes[:clusterHotspots]
+		}
+		row.MailboxHotspots = m.Processes
+	}
+}
@@ -0,0 +1,189 @@
+package observer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"ergo.services/ergo/act"
+	"ergo.services/ergo/gen"
+)
+
+const (
+	cronEvent gen.Atom = "observer_cron"
+
+	cronRefreshInterval = time.Second
+	cronScheduleWindow  = 24 * time.Hour
+)
+
+// cronState is sent as the "cron" SSE event
+type cronState struct {
+	Next  time.Time  `json:"Next"`
+	Spool []gen.Atom `json:"Spool"`
+	Jobs  []cronJob  `json:"Jobs"`
+}
+
+type cronJob struct {
+	gen.CronJobInfo
+
+	// NextRun within the next 24 hours, zero if the job is disabled or not scheduled
+	NextRun time.Time `json:"NextRun"`
+
+	// Runnable is set if the job can be run from the dashboard (Options.CronActions)
+	Runnable bool `json:"Runnable"`
+}
+
+// requestCronAction sent via Call from session to cron, returns error or nil
+type requestCronAction struct {
+	Action string // run, enable, disable
+	Job    gen.Atom
+}
+
+type messageCronTick struct{}
+
+func factory_cron() gen.ProcessBehavior {
+	return &cron{}
+}
+
+// cron publishes the state of the node's scheduler with the observer_cron event
+// while there are sessions subscribed to it, and serves the cron actions.
+// gen.Cron doesn't expose the job actions, so only the jobs with the action
+// given in Options.CronActions can be run on demand.
+type cron struct {
+	act.Actor
+
+	token   gen.Ref
+	actions map[gen.Atom]gen.CronAction
+	active  bool // the event has subscribers
+	ticking bool
+	last    []byte // the latest published state
+}
+
+func (c *cron) Init(args ...any) error {
+	c.Log().SetLogger("default")
+	c.actions = args[0].(Options).CronActions
+
+	token, err := c.RegisterEvent(cronEvent, gen.EventOptions{Notify: true, Buffer: 1})
+	if err != nil {
+		return err
+	}
+	c.token = token
+	return nil
+}
+
+func (c *cron) HandleMessage(from gen.PID, message any) error {
+	switch message.(type) {
+	case gen.MessageEventStart:
+		c.active = true
+		if c.ticking == false {
+			c.ticking = true
+			c.Send(c.PID(), messageCronTick{})
+		}
+
+	case gen.MessageEventStop:
+		c.active = false
+		c.last = nil
+
+	case messageCronTick:
+		if c.active == false {
+			c.ticking = false
+			return nil
+		}
+		c.publish()
+		c.SendAfter(c.PID(), messageCronTick{}, cronRefreshInterval)
+
+	default:
+		c.Log().Warning("unknown message from %s: %#v", from, message)
+	}
+	return nil
+}
+
+func (c *cron) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	r, ok := request.(requestCronAction)
+	if ok == false {
+		return nil, gen.ErrUnsupported
+	}
+
+	scheduler := c.Node().Cron()
+	if _, err := scheduler.JobInfo(r.Job); err != nil {
+		return err, nil
+	}
+
+	var err error
+	switch r.Action {
+	case "run":
+		action, exist := c.actions[r.Job]
+		if exist == false {
+			return fmt.Errorf("the action of job %s is not accessible, add it to Options.CronActions", r.Job), nil
+		}
+		err = action.Do(r.Job, c.Node(), time.Now())
+	case "enable":
+		err = scheduler.EnableJob(r.Job)
+	case "disable":
+		err = scheduler.DisableJob(r.Job)
+	default:
+		err = fmt.Errorf("unknown cron action %s", r.Action)
+	}
+	if err != nil {
+		return err, nil
+	}
+	c.Log().Info("cron job %s: %s", r.Job, r.Action)
+
+	if c.active {
+		c.publish()
+	}
+	return nil, nil
+}
+
+func (c *cron) Terminate(reason error) {
+	c.Log().Info("cron terminated: %s", reason)
+}
+
+// publish sends the scheduler state if it has changed since the last time
+func (c *cron) publish() {
+	scheduler := c.Node().Cron()
+	info := scheduler.Info()
+	state := cronState{
+		Next:  info.Next,
+		Spool: info.Spool,
+		Jobs:  make([]cronJob, 0, len(info.Jobs)),
+	}
+
+	// the nearest run of each job
+	next := make(map[gen.Atom]time.Time)
+	for _, s := range scheduler.Schedule(time.Now(), cronScheduleWindow) {
+		for _, job := range s.Jobs {
+			if _, exist := next[job]; exist == false {
+				next[job] = s.Time
+			}
+		}
+	}
+
+	for _, job := range info.Jobs {
+		_, runnable := c.actions[job.Name]
+		state.Jobs = append(state.Jobs, cronJob{
+			CronJobInfo: job,
+			NextRun:     next[job.Name],
+			Runnable:    runnable,
+		})
+	}
+	sort.Slice(state.Jobs, func(i, j int) bool {
+		return state.Jobs[i].Name < state.Jobs[j].Name
+	})
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		c.Log().Error("unable to marshal cron state: %s", err)
+		return
+	}
+	if bytes.Equal(data, c.last) {
+		return
+	}
+	c.last = data
+
+	if err := c.SendEvent(cronEvent, c.token, state); err != nil {
+		c.Log().Error("unable to send cron event: %s", err)
+	}
+}
@@ -1,8 +1,9 @@
 module ergo.services/application/observer
 
-go 1.20
+go 1.24.0
 
 require (
 	ergo.services/ergo v1.999.321-0.20260319110303-2a611af396ab
 	ergo.services/meta/sse v0.2.0
+	github.com/google/pprof v0.0.0-20260302011040-a15ffb7f9dcc
 )
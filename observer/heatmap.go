@@ -0,0 +1,238 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"ergo.services/ergo/app/system/inspect"
+)
+
+const (
+	defaultHeatmapTop = 20
+	maxHeatmapTop     = 1000
+
+	// heatmapScanLimit differs from the limit of the process_list range scan,
+	// so the heatmap gets its own inspect event
+	heatmapScanLimit = 100000
+)
+
+// processHeatmap is sent as the "process_heatmap" SSE event. Rates are per second
+// between two inspect ticks, RunningShare is the part of the interval the process
+// (or the group) was running.
+type processHeatmap struct {
+	Time      time.Time `json:"Time"`
+	Interval  float64   `json:"Interval"` // seconds
+	Processes int       `json:"Processes"`
+	Sort      string    `json:"Sort"`
+
+	Hotspots     []heatmapProcess `json:"Hotspots"`
+	Applications []heatmapGroup   `json:"Applications"`
+	Behaviors    []heatmapGroup   `json:"Behaviors"`
+}
+
+type heatmapProcess struct {
+	PID         json.RawMessage `json:"PID"`
+	Name        string          `json:"Name"`
+	Application string          `json:"Application"`
+	Behavior    string          `json:"Behavior"`
+	heatmapValues
+}
+
+type heatmapGroup struct {
+	Name      string `json:"Name"`
+	Processes int    `json:"Processes"`
+	heatmapValues
+}
+
+type heatmapValues struct {
+	MessagesIn      float64 `json:"MessagesIn"`
+	MessagesOut     float64 `json:"MessagesOut"`
+	RunningShare    float64 `json:"RunningShare"`
+	MessagesMailbox uint64  `json:"MessagesMailbox"`
+	// MailboxLatency is the age of the oldest message in the mailbox (nanoseconds),
+	// the max one for the group. -1 if the node is built without -tags=latency
+	MailboxLatency int64 `json:"MailboxLatency"`
+}
+
+func (v *heatmapValues) add(p heatmapValues) {
+	v.MessagesIn += p.MessagesIn
+	v.MessagesOut += p.MessagesOut
+	v.RunningShare += p.RunningShare
+	v.MessagesMailbox += p.MessagesMailbox
+	if p.MailboxLatency > v.MailboxLatency {
+		v.MailboxLatency = p.MailboxLatency
+	}
+}
+
+// sortValue returns the value the hotspots and groups are ordered by
+func (v heatmapValues) sortValue(by string) float64 {
+	switch by {
+	case "messages_in":
+		return v.MessagesIn
+	case "messages_out":
+		return v.MessagesOut
+	case "mailbox":
+		return float64(v.MessagesMailbox)
+	case "latency":
+		return float64(v.MailboxLatency)
+	}
+	return v.RunningShare
+}
+
+// heatmap computes the deltas of the process counters between the inspect ticks
+// of the process_heatmap subscription. It is kept by the session per inspect event.
+type heatmap struct {
+	top  int
+	sort string
+
+	last     time.Time
+	counters map[string]processCounters // PID → counters of the previous tick
+}
+
+// newHeatmap creates the heatmap with the subscription args: top (number of hotspots)
+// and sort (running, messages_in, messages_out, mailbox, latency)
+func newHeatmap(args map[string]any) (*heatmap, error) {
+	h := &heatmap{top: defaultHeatmapTop, sort: "running"}
+	if v, ok := args["top"].(float64); ok && v >= 1 {
+		h.top = int(v)
+		if h.top > maxHeatmapTop {
+			h.top = maxHeatmapTop
+		}
+	}
+	if v, _ := args["sort"].(string); v != "" {
+		switch v {
+		case "running", "messages_in", "messages_out", "mailbox", "latency":
+			h.sort = v
+		default:
+			return nil, fmt.Errorf("unknown heatmap sort %q", v)
+		}
+	}
+	return h, nil
+}
+
+// update takes the inspect_process_range event and returns the heatmap.
+// Returns nil on the first tick, there is nothing to compare with.
+func (h *heatmap) update(data []byte) []byte {
+	var event struct {
+		Processes []struct {
+			PID             json.RawMessage `json:"PID"`
+			Name            string          `json:"Name"`
+			Application     string          `json:"Application"`
+			Behavior        string          `json:"Behavior"`
+			MessagesIn      uint64          `json:"MessagesIn"`
+			MessagesOut     uint64          `json:"MessagesOut"`
+			MessagesMailbox uint64          `json:"MessagesMailbox"`
+			MailboxLatency  int64           `json:"MailboxLatency"`
+			RunningTime     uint64          `json:"RunningTime"`
+		} `json:"Processes"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	interval := now.Sub(h.last)
+	first := h.last.IsZero()
+	h.last = now
+
+	// counters of terminated processes are forgotten
+	counters := make(map[string]processCounters, len(event.Processes))
+	result := processHeatmap{
+		Time:      now,
+		Interval:  interval.Seconds(),
+		Processes: len(event.Processes),
+		Sort:      h.sort,
+	}
+	apps := make(map[string]*heatmapGroup)
+	behaviors := make(map[string]*heatmapGroup)
+	group := func(groups map[string]*heatmapGroup, name string, v heatmapValues) {
+		g, exist := groups[name]
+		if exist == false {
+			g = &heatmapGroup{Name: name}
+			g.MailboxLatency = v.MailboxLatency
+			groups[name] = g
+		}
+		g.Processes++
+		g.add(v)
+	}
+
+	for _, p := range event.Processes {
+		current := processCounters{
+			messagesIn:  p.MessagesIn,
+			messagesOut: p.MessagesOut,
+			runningTime: p.RunningTime,
+		}
+		key := string(p.PID)
+		counters[key] = current
+		if first {
+			continue
+		}
+		prev, exist := h.counters[key]
+		if exist == false {
+			// started within the interval, its counters are taken as the base
+			prev = current
+		}
+		v := heatmapValues{
+			MessagesIn:      float64(delta(current.messagesIn, prev.messagesIn)) / interval.Seconds(),
+			MessagesOut:     float64(delta(current.messagesOut, prev.messagesOut)) / interval.Seconds(),
+			RunningShare:    float64(delta(current.runningTime, prev.runningTime)) / float64(interval),
+			MessagesMailbox: p.MessagesMailbox,
+			MailboxLatency:  p.MailboxLatency,
+		}
+		result.Hotspots = append(result.Hotspots, heatmapProcess{
+			PID:           p.PID,
+			Name:          p.Name,
+			Application:   p.Application,
+			Behavior:      p.Behavior,
+			heatmapValues: v,
+		})
+		group(apps, p.Application, v)
+		group(behaviors, p.Behavior, v)
+	}
+	h.counters = counters
+	if first || interval <= 0 {
+		return nil
+	}
+
+	sort.Slice(result.Hotspots, func(i, j int) bool {
+		return result.Hotspots[i].sortValue(h.sort) > result.Hotspots[j].sortValue(h.sort)
+	})
+	if len(result.Hotspots) > h.top {
+		result.Hotspots = result.Hotspots[:h.top]
+	}
+	result.Applications = sortGroups(apps, h.sort)
+	result.Behaviors = sortGroups(behaviors, h.sort)
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func sortGroups(groups map[string]*heatmapGroup, by string) []heatmapGroup {
+	list := make([]heatmapGroup, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, *g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].sortValue(by) > list[j].sortValue(by)
+	})
+	return list
+}
+
+// heatmapRequest builds the range scan of the process_heatmap subscription,
+// filtered by the args namePattern, behavior and application
+func heatmapRequest(args map[string]any) inspect.RequestInspectProcessRange {
+	namePattern, _ := args["namePattern"].(string)
+	behavior, _ := args["behavior"].(string)
+	application, _ := args["application"].(string)
+	return inspect.RequestInspectProcessRange{
+		Limit:       heatmapScanLimit,
+		Name:        namePattern,
+		Behavior:    behavior,
+		Application: application,
+	}
+}
@@ -1,6 +1,7 @@
 package observer
 
 import (
+	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
@@ -59,3 +60,67 @@ func str2alias(node gen.Atom, creation int64, s string) (gen.Alias, error) {
 	alias.ID[2] = uint64(id3)
 	return alias, nil
 }
+
+// connectNode ensures network connection to the target node.
+// If already connected — no-op. Otherwise tries registrar, then explicit route from args.
+func connectNode(local gen.Node, node gen.Atom, args map[string]any) error {
+	// connecting to self
+	if node == local.Name() {
+		return nil
+	}
+
+	// already connected
+	if _, err := local.Network().Node(node); err == nil {
+		return nil
+	}
+
+	// build route from args
+	nr := gen.NetworkRoute{}
+
+	// try registrar first
+	if reg, err := local.Network().Registrar(); err == nil {
+		if routes, err := reg.Resolver().Resolve(node); err == nil && len(routes) > 0 {
+			nr.Route = routes[0]
+		}
+	}
+
+	// override with explicit args
+	if v, ok := args["Cookie"].(string); ok && v != "" {
+		nr.Cookie = v
+	}
+	if v, ok := args["Host"].(string); ok && v != "" {
+		nr.Route.Host = v
+	}
+	if v, ok := args["Port"].(float64); ok && v > 0 {
+		nr.Route.Port = uint16(v)
+	}
+	if v, ok := args["TLS"].(bool); ok && v {
+		nr.Route.TLS = true
+	}
+
+	// if we have any route info, use explicit route
+	if nr.Route.Host != "" || nr.Route.Port > 0 || nr.Cookie != "" {
+		_, err := local.Network().GetNodeWithRoute(node, nr)
+		return err
+	}
+
+	// fallback: auto-discovery
+	_, err := local.Network().GetNode(node)
+	return err
+}
+
+// tagNode adds the "ObservedNode" field to the JSON object, so the frontend can tell
+// apart events of different nodes when a session observes several nodes at once.
+func tagNode(data []byte, node gen.Atom) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	tag, _ := json.Marshal(node)
+	out := make([]byte, 0, len(data)+len(tag)+16)
+	out = append(out, `{"ObservedNode":`...)
+	out = append(out, tag...)
+	if data[1] != '}' {
+		out = append(out, ',')
+	}
+	return append(out, data[1:]...)
+}
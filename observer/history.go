@@ -0,0 +1,217 @@
+package observer
+
+import (
+	"sort"
+	"time"
+
+	"ergo.services/ergo/act"
+	"ergo.services/ergo/gen"
+)
+
+const (
+	defaultHistoryResolution   = 10 * time.Second
+	defaultHistoryTopProcesses = 10
+)
+
+type HistoryOptions struct {
+	// Duration of the kept history. Default: 1h. Negative value disables history
+	Duration time.Duration
+
+	// Resolution is the sampling interval. Default: 10s
+	Resolution time.Duration
+
+	// TopProcesses is the number of the busiest processes kept in each sample.
+	// Default: 10. Negative value disables
+	TopProcesses int
+}
+
+// historySample is the node state at the moment of sampling. Messages and bytes
+// are the deltas since the previous sample.
+type historySample struct {
+	Time             time.Time        `json:"Time"`
+	ProcessesTotal   int64            `json:"ProcessesTotal"`
+	ProcessesRunning int64            `json:"ProcessesRunning"`
+	MemoryUsed       uint64           `json:"MemoryUsed"`
+	MemoryAlloc      uint64           `json:"MemoryAlloc"`
+	MessagesIn       uint64           `json:"MessagesIn"`
+	MessagesOut      uint64           `json:"MessagesOut"`
+	BytesIn          uint64           `json:"BytesIn"`
+	BytesOut         uint64           `json:"BytesOut"`
+	TopProcesses     []historyProcess `json:"TopProcesses"`
+}
+
+// historyProcess is a process among the busiest ones (by messages received) within the sample
+type historyProcess struct {
+	PID             gen.PID  `json:"PID"`
+	Name            gen.Atom `json:"Name"`
+	MessagesIn      uint64   `json:"MessagesIn"`
+	MessagesOut     uint64   `json:"MessagesOut"`
+	MessagesMailbox uint64   `json:"MessagesMailbox"`
+	RunningTime     uint64   `json:"RunningTime"` // nanoseconds within the sample
+}
+
+// nodeHistory is sent as the "node_history" SSE event on node_info subscription
+type nodeHistory struct {
+	Resolution time.Duration   `json:"Resolution"`
+	Samples    []historySample `json:"Samples"`
+}
+
+type processCounters struct {
+	messagesIn  uint64
+	messagesOut uint64
+	runningTime uint64
+}
+
+type networkCounters struct {
+	bytesIn  uint64
+	bytesOut uint64
+}
+
+// requestHistory sent via Call from session/rest to history, returns nodeHistory
+type requestHistory struct{}
+
+type messageHistoryTick struct{}
+
+func factory_history() gen.ProcessBehavior {
+	return &history{}
+}
+
+// history keeps a rolling buffer of the local node metrics, so a client gets
+// the recent past right after subscribing. This is server-side only: it's sent
+// via SSE and GET /api/v1/history, the bundled frontend doesn't render it yet.
+type history struct {
+	act.Actor
+
+	resolution time.Duration
+	size       int
+	top        int
+	samples    []historySample
+
+	processes   map[gen.PID]processCounters
+	connections map[gen.Atom]networkCounters
+}
+
+func (h *history) Init(args ...any) error {
+	h.Log().SetLogger("default")
+	options := args[0].(Options).History
+	h.resolution = options.Resolution
+	if h.resolution <= 0 {
+		h.resolution = defaultHistoryResolution
+	}
+	h.size = int(options.Duration / h.resolution)
+	if h.size < 1 {
+		h.size = 1
+	}
+	h.top = options.TopProcesses
+	if h.top == 0 {
+		h.top = defaultHistoryTopProcesses
+	}
+	h.processes = make(map[gen.PID]processCounters)
+	h.connections = make(map[gen.Atom]networkCounters)
+
+	// the first sample only initializes the counters
+	h.sample()
+	h.samples = h.samples[:0]
+	h.SendAfter(h.PID(), messageHistoryTick{}, h.resolution)
+	return nil
+}
+
+func (h *history) HandleMessage(from gen.PID, message any) error {
+	switch message.(type) {
+	case messageHistoryTick:
+		h.sample()
+		h.SendAfter(h.PID(), messageHistoryTick{}, h.resolution)
+	default:
+		h.Log().Warning("unknown message from %s: %#v", from, message)
+	}
+	return nil
+}
+
+func (h *history) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	switch request.(type) {
+	case requestHistory:
+		samples := make([]historySample, len(h.samples))
+		copy(samples, h.samples)
+		return nodeHistory{Resolution: h.resolution, Samples: samples}, nil
+	}
+	return nil, gen.ErrUnsupported
+}
+
+func (h *history) sample() {
+	sample := historySample{Time: time.Now()}
+
+	if info, err := h.Node().Info(); err == nil {
+		sample.ProcessesTotal = int64(info.ProcessesTotal)
+		sample.ProcessesRunning = int64(info.ProcessesRunning)
+		sample.MemoryUsed = uint64(info.MemoryUsed)
+		sample.MemoryAlloc = uint64(info.MemoryAlloc)
+	}
+
+	// per process deltas. Counters of terminated processes are forgotten
+	processes := make(map[gen.PID]processCounters, len(h.processes))
+	var busy []historyProcess
+	h.Node().ProcessRangeShortInfo(func(info gen.ProcessShortInfo) bool {
+		current := processCounters{
+			messagesIn:  info.MessagesIn,
+			messagesOut: info.MessagesOut,
+			runningTime: uint64(info.RunningTime),
+		}
+		processes[info.PID] = current
+		prev := h.processes[info.PID]
+		p := historyProcess{
+			PID:             info.PID,
+			Name:            info.Name,
+			MessagesIn:      delta(current.messagesIn, prev.messagesIn),
+			MessagesOut:     delta(current.messagesOut, prev.messagesOut),
+			MessagesMailbox: info.MessagesMailbox,
+			RunningTime:     delta(current.runningTime, prev.runningTime),
+		}
+		sample.MessagesIn += p.MessagesIn
+		sample.MessagesOut += p.MessagesOut
+		if p.MessagesIn > 0 || p.MessagesMailbox > 0 {
+			busy = append(busy, p)
+		}
+		return true
+	})
+	h.processes = processes
+
+	if h.top > 0 {
+		sort.Slice(busy, func(i, j int) bool {
+			return busy[i].MessagesIn > busy[j].MessagesIn
+		})
+		if len(busy) > h.top {
+			busy = busy[:h.top]
+		}
+		sample.TopProcesses = busy
+	}
+
+	// per connection deltas
+	connections := make(map[gen.Atom]networkCounters, len(h.connections))
+	for _, node := range h.Node().Network().Nodes() {
+		remote, err := h.Node().Network().Node(node)
+		if err != nil {
+			continue
+		}
+		info := remote.Info()
+		current := networkCounters{bytesIn: uint64(info.BytesIn), bytesOut: uint64(info.BytesOut)}
+		connections[node] = current
+		prev := h.connections[node]
+		sample.BytesIn += delta(current.bytesIn, prev.bytesIn)
+		sample.BytesOut += delta(current.bytesOut, prev.bytesOut)
+	}
+	h.connections = connections
+
+	if len(h.samples) == h.size {
+		h.samples = h.samples[1:]
+	}
+	h.samples = append(h.samples, sample)
+}
+
+// delta of the counter since the previous sample. The counter started over
+// (e.g. the node reconnected) if it is less than the previous value.
+func delta(current, prev uint64) uint64 {
+	if current < prev {
+		return current
+	}
+	return current - prev
+}
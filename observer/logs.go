@@ -0,0 +1,329 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"ergo.services/ergo/act"
+	"ergo.services/ergo/gen"
+	"ergo.services/ergo/meta"
+)
+
+const defaultLogBacklog = 1000
+
+// logEntry is a log message in the format of the inspect_log event entries
+type logEntry struct {
+	Source    string `json:"Source"` // process, meta, node, network
+	Timestamp int64  `json:"Timestamp"`
+	Level     string `json:"Level"`
+	Message   string `json:"Message"`
+	PID       string `json:"PID,omitempty"`
+	Name      string `json:"Name,omitempty"`
+	Behavior  string `json:"Behavior,omitempty"`
+	Meta      string `json:"Meta,omitempty"`
+	Parent    string `json:"Parent,omitempty"`
+	Peer      string `json:"Peer,omitempty"`
+}
+
+// logFilter is the server-side filter of the log subscription and the backlog.
+// Empty fields match everything.
+type logFilter struct {
+	Source      string
+	PID         string
+	Name        string
+	Application gen.Atom
+	Match       *regexp.Regexp
+	Levels      map[string]bool
+
+	apps map[string]gen.Atom // PID → application, resolved on demand
+}
+
+func (f *logFilter) empty() bool {
+	return f.Source == "" && f.PID == "" && f.Name == "" && f.Application == "" &&
+		f.Match == nil && len(f.Levels) == 0
+}
+
+// parseLogFilter builds the filter from the subscription args or the query
+// parameters (source, pid, name, application, match, levels)
+func parseLogFilter(get func(string) string, levels []string) (*logFilter, error) {
+	f := &logFilter{
+		Source:      get("source"),
+		PID:         get("pid"),
+		Name:        get("name"),
+		Application: gen.Atom(get("application")),
+	}
+	switch f.Source {
+	case "", "process", "meta", "node", "network":
+	default:
+		return nil, fmt.Errorf("unknown log source %q", f.Source)
+	}
+	if match := get("match"); match != "" {
+		re, err := regexp.Compile(match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match: %s", err)
+		}
+		f.Match = re
+	}
+	for _, level := range levels {
+		if f.Levels == nil {
+			f.Levels = make(map[string]bool)
+		}
+		f.Levels[level] = true
+	}
+	return f, nil
+}
+
+// match checks the entry. The application of the process is resolved on the node,
+// so the application filter works for the local node only.
+func (f *logFilter) match(node gen.Node, e logEntry) bool {
+	if f.Source != "" && e.Source != f.Source {
+		return false
+	}
+	if len(f.Levels) > 0 && f.Levels[e.Level] == false {
+		return false
+	}
+	if f.PID != "" && e.PID != f.PID && e.Parent != f.PID {
+		return false
+	}
+	if f.Name != "" && e.Name != f.Name {
+		return false
+	}
+	if f.Application != "" {
+		pid := e.PID
+		if pid == "" {
+			pid = e.Parent // meta process belongs to the application of its parent
+		}
+		if pid == "" || f.application(node, pid) != f.Application {
+			return false
+		}
+	}
+	if f.Match != nil && f.Match.MatchString(e.Message) == false {
+		return false
+	}
+	return true
+}
+
+func (f *logFilter) application(node gen.Node, pid string) gen.Atom {
+	if app, exist := f.apps[pid]; exist {
+		return app
+	}
+	if f.apps == nil || len(f.apps) > 10000 {
+		f.apps = make(map[string]gen.Atom)
+	}
+	var app gen.Atom
+	if p, err := str2pid(node.Name(), node.Creation(), pid); err == nil {
+		if info, err := node.ProcessInfo(p); err == nil {
+			app = info.Application
+		}
+	}
+	f.apps[pid] = app
+	return app
+}
+
+// filterEvent applies the filter to the inspect_log event. Returns nil if nothing is left.
+func (f *logFilter) filterEvent(node gen.Node, data []byte) []byte {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(data, &event); err != nil {
+		return data
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(event["Entries"], &entries); err != nil {
+		return data
+	}
+
+	kept := make([]json.RawMessage, 0, len(entries))
+	for _, raw := range entries {
+		var e logEntry
+		if err := json.Unmarshal(raw, &e); err != nil || f.match(node, e) {
+			kept = append(kept, raw)
+		}
+	}
+
+	var suppressed int64
+	json.Unmarshal(event["Suppressed"], &suppressed)
+	if len(kept) == 0 && suppressed == 0 {
+		return nil
+	}
+	event["Entries"], _ = json.Marshal(kept)
+	out, err := json.Marshal(event)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// requestLogBacklog sent via Call from session/rest to the log process, returns []logEntry
+type requestLogBacklog struct {
+	Filter *logFilter
+	Limit  int // the most recent entries, 0 = all
+}
+
+func factory_logs() gen.ProcessBehavior {
+	return &logs{}
+}
+
+// logs keeps the recent log messages of the node, so a dashboard opening
+// the log view sees them, and they can be downloaded.
+type logs struct {
+	act.Actor
+
+	size    int
+	entries []logEntry
+}
+
+func (l *logs) Init(args ...any) error {
+	l.Log().SetLogger("default")
+	l.size = args[0].(Options).LogBacklog
+
+	// debug and trace would evict the rest of the backlog within seconds
+	levels := []gen.LogLevel{gen.LogLevelInfo, gen.LogLevelWarning, gen.LogLevelError, gen.LogLevelPanic}
+	return l.Node().LoggerAddPID(l.PID(), string(logsName), levels...)
+}
+
+func (l *logs) HandleLog(message gen.MessageLog) error {
+	e := logEntry{
+		Timestamp: message.Time.UnixNano(),
+		Level:     message.Level.String(),
+		Message:   fmt.Sprintf(message.Format, message.Args...),
+	}
+	switch src := message.Source.(type) {
+	case gen.MessageLogProcess:
+		e.Source = "process"
+		e.PID = src.PID.String()
+		e.Name = string(src.Name)
+		e.Behavior = src.Behavior
+	case gen.MessageLogMeta:
+		e.Source = "meta"
+		e.Meta = src.Meta.String()
+		e.Parent = src.Parent.String()
+		e.Behavior = src.Behavior
+	case gen.MessageLogNode:
+		e.Source = "node"
+	case gen.MessageLogNetwork:
+		e.Source = "network"
+		e.Peer = string(src.Peer)
+	}
+
+	if len(l.entries) == l.size {
+		l.entries = l.entries[1:]
+	}
+	l.entries = append(l.entries, e)
+	return nil
+}
+
+func (l *logs) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	r, ok := request.(requestLogBacklog)
+	if ok == false {
+		return nil, gen.ErrUnsupported
+	}
+	var entries []logEntry
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		if r.Limit > 0 && len(entries) == r.Limit {
+			break
+		}
+		if r.Filter != nil && r.Filter.match(l.Node(), l.entries[i]) == false {
+			continue
+		}
+		entries = append(entries, l.entries[i])
+	}
+	// oldest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func (l *logs) Terminate(reason error) {
+	l.Node().LoggerDeletePID(l.PID())
+}
+
+// handleLogDownload serves GET /api/v1/log/download: the buffered log of the node
+// running Observer as text (default) or JSON lines (format=jsonl), filtered
+// by the query parameters source, pid, name, application, match and level.
+func (r *rest) handleLogDownload(m meta.MessageWebRequest) {
+	query := m.Request.URL.Query()
+	filter, err := parseLogFilter(query.Get, splitList(query.Get("level")))
+	if err != nil {
+		writeJSON(m.Response, http.StatusBadRequest, apiResponse{Error: err.Error()})
+		return
+	}
+	result, err := r.Call(logsName, requestLogBacklog{Filter: filter})
+	if err == gen.ErrProcessUnknown {
+		writeJSON(m.Response, http.StatusNotFound, apiResponse{Error: "log backlog is disabled (Options.LogBacklog is negative)"})
+		return
+	}
+	if err != nil {
+		writeJSON(m.Response, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+		return
+	}
+	entries, _ := result.([]logEntry)
+
+	name := fmt.Sprintf("%s-%s", r.Node().Name(), time.Now().Format("20060102-150405"))
+	w := m.Response
+	if query.Get("format") == "jsonl" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".jsonl"))
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			enc.Encode(e)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".log"))
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s [%s] %s: %s\n",
+			time.Unix(0, e.Timestamp).Format(time.RFC3339Nano), e.Level, e.origin(), e.Message)
+	}
+}
+
+// origin describes the source of the entry for the text format
+func (e logEntry) origin() string {
+	switch e.Source {
+	case "process":
+		if e.Name != "" {
+			return fmt.Sprintf("%s (%s) %s", e.PID, e.Name, e.Behavior)
+		}
+		return fmt.Sprintf("%s %s", e.PID, e.Behavior)
+	case "meta":
+		return fmt.Sprintf("%s (parent: %s) %s", e.Meta, e.Parent, e.Behavior)
+	case "network":
+		return "network " + e.Peer
+	}
+	return e.Source
+}
+
+// splitList splits the comma separated query parameter
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// argsGetter returns the string args of the subscription by name
+func argsGetter(args map[string]any) func(string) string {
+	return func(name string) string {
+		v, _ := args[name].(string)
+		return v
+	}
+}
+
+// logLevels returns the "levels" arg of the log subscription
+func logLevels(args map[string]any) []string {
+	var levels []string
+	list, _ := args["levels"].([]any)
+	for _, l := range list {
+		if level, ok := l.(string); ok {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
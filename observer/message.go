@@ -1,10 +1,20 @@
 package observer
 
+import (
+	"time"
+
+	"ergo.services/ergo/gen"
+)
+
 // commandRequest sent via Call from POST worker to session actor
 type commandRequest struct {
 	Command string         // "subscribe", "unsubscribe", "switch"
 	Type    string         // subscription type (node_info, process_list, etc.)
 	Args    map[string]any // type-specific arguments
+	Node    string         // node to subscribe on. Empty = primary observed node
+
+	Identity   string // authenticated identity of the HTTP request, empty if auth is disabled
+	RemoteAddr string // of the HTTP request
 }
 
 // apiResponse returned from session actor to POST worker
@@ -16,6 +26,76 @@ type apiResponse struct {
 
 // actionRequest sent via Call from POST worker to session actor for do/* commands
 type actionRequest struct {
-	Action string         // "send", "send_exit", "kill", "set_log_level", etc.
+	Action string // "send", "send_exit", "kill", "set_log_level", etc.
 	Args   map[string]any
+
+	Identity   string // authenticated identity of the HTTP request, empty if auth is disabled
+	RemoteAddr string // of the HTTP request
+}
+
+// sessionOptions passed by mgr to every session it spawns
+type sessionOptions struct {
+	Capabilities capabilities
+	GracePeriod  time.Duration // see Options.SessionGracePeriod
+	RecordDir    string        // see Options.RecordDir
+	RecordAll    bool          // see Options.RecordAll
+	RecordLimits recordLimits  // see Options.RecordMaxSize, Options.RecordMaxFiles
+
+	IdleTimeout      time.Duration // see Options.SessionIdleTimeout
+	MaxSubscriptions int           // see Options.MaxSubscriptions
+
+	CoalesceWindow time.Duration // see Options.EventCoalesceWindow
+	MaxPending     int64         // see Options.MaxPendingEvents
+}
+
+// sessionSummary sent by session to mgr whenever its state changes.
+// Returned by the admin API GET /api/v1/sessions.
+type sessionSummary struct {
+	ID            string    `json:"ID"`
+	Identity      string    `json:"Identity,omitempty"`
+	RemoteAddr    string    `json:"RemoteAddr"`
+	Node          gen.Atom  `json:"Node"`
+	Subscriptions int       `json:"Subscriptions"`
+	Started       time.Time `json:"Started"`
+	Age           string    `json:"Age"`
+	LastActive    time.Time `json:"LastActive"`
+	Recording     bool      `json:"Recording"`
+	Detached      bool      `json:"Detached"`
+}
+
+// requestAlertList sent via Call from rest to alerts, returns []Alert (firing)
+type requestAlertList struct{}
+
+// requestSessionList sent via Call from rest to mgr, returns []sessionSummary
+type requestSessionList struct{}
+
+// requestSessionClose sent via Call from rest to mgr to terminate the session
+type requestSessionClose struct {
+	ID     string
+	Reason string
+}
+
+// messageSessionClose sent by mgr to the session to be terminated
+type messageSessionClose struct {
+	Reason string
+}
+
+// messageSessionIdle sent by session to itself to check the idle timeout
+type messageSessionIdle struct{}
+
+// messageResume sent by mgr to the session the browser was attached to
+// when it reconnects with Last-Event-ID
+type messageResume struct {
+	SSE         gen.Alias // new SSE connection
+	LastEventID int64     // last event received by the browser
+	Fresh       gen.Atom  // session spawned for the new connection, replaced on resume (if any)
+	Identity    string    // authenticated identity of the new connection
+}
+
+// messageReplaced sent by the resumed session to the fresh one spawned for the same SSE connection
+type messageReplaced struct{}
+
+// messageSessionExpire sent by session to itself after SSE disconnect (grace period)
+type messageSessionExpire struct {
+	DetachedAt time.Time
 }
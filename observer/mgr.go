@@ -3,6 +3,13 @@ package observer
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"ergo.services/ergo/act"
 	"ergo.services/ergo/gen"
@@ -17,9 +24,35 @@ func factory_mgr() gen.ProcessBehavior {
 // SSE handler has ProcessPool: [mgrName], so all SSE messages come here.
 type mgr struct {
 	act.Actor
+	options     sessionOptions
+	maxSessions int
+	connections map[gen.Alias]gen.Atom     // SSE connection → session spawned for it
+	identities  map[gen.Alias]string       // SSE connection → authenticated identity of its request
+	sessions    map[gen.PID]sessionSummary // live sessions (monitored), for the admin API
 }
 
 func (m *mgr) Init(args ...any) error {
+	options := args[0].(Options)
+	m.options = sessionOptions{
+		Capabilities: newCapabilities(options),
+		GracePeriod:  options.SessionGracePeriod,
+		RecordDir:    options.RecordDir,
+		RecordAll:    options.RecordAll,
+		RecordLimits: recordLimits{maxSize: options.RecordMaxSize, maxFiles: options.RecordMaxFiles},
+
+		IdleTimeout:      options.SessionIdleTimeout,
+		MaxSubscriptions: options.MaxSubscriptions,
+
+		CoalesceWindow: options.EventCoalesceWindow,
+		MaxPending:     options.MaxPendingEvents,
+	}
+	if m.options.MaxPending > 0 && m.options.CoalesceWindow <= 0 {
+		m.options.CoalesceWindow = defaultCoalesceWindow
+	}
+	m.maxSessions = options.MaxSessions
+	m.connections = make(map[gen.Alias]gen.Atom)
+	m.identities = make(map[gen.Alias]string)
+	m.sessions = make(map[gen.PID]sessionSummary)
 	m.Log().SetLogger("default")
 	m.Log().Info("session manager started")
 	return nil
@@ -31,12 +64,21 @@ func (m *mgr) HandleMessage(from gen.PID, message any) error {
 		m.handleConnect(msg)
 
 	case sse.MessageDisconnect:
-		// no-op: session terminates via LinkAlias when SSE meta dies
+		// session handles it via MonitorAlias when SSE meta dies
+		delete(m.connections, msg.ID)
+		delete(m.identities, msg.ID)
 		m.Log().Debug("SSE disconnect: %s", msg.ID)
 
 	case sse.MessageLastEventID:
-		// ignored in v2: each reconnect is a fresh session
-		m.Log().Debug("SSE last event ID: %s (ignored)", msg.LastEventID)
+		m.handleLastEventID(msg)
+
+	case sessionSummary:
+		if _, exist := m.sessions[from]; exist {
+			m.sessions[from] = msg
+		}
+
+	case gen.MessageDownPID:
+		delete(m.sessions, msg.PID)
 
 	default:
 		m.Log().Warning("unknown message from %s: %#v", from, message)
@@ -44,26 +86,188 @@ func (m *mgr) HandleMessage(from gen.PID, message any) error {
 	return nil
 }
 
+func (m *mgr) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	switch r := request.(type) {
+	case requestSessionList:
+		list := make([]sessionSummary, 0, len(m.sessions))
+		for _, summary := range m.sessions {
+			summary.Age = time.Since(summary.Started).Truncate(time.Second).String()
+			list = append(list, summary)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Started.Before(list[j].Started)
+		})
+		return list, nil
+
+	case requestSessionClose:
+		for pid, summary := range m.sessions {
+			if summary.ID != r.ID {
+				continue
+			}
+			if err := m.Send(pid, messageSessionClose{Reason: r.Reason}); err != nil {
+				return err, nil
+			}
+			m.Log().Info("session %s is closed: %s", r.ID, r.Reason)
+			return true, nil
+		}
+		return fmt.Errorf("session %s not found", r.ID), nil
+	}
+	return nil, gen.ErrUnsupported
+}
+
 func (m *mgr) handleConnect(msg sse.MessageConnect) {
+	// the session is bound to the identity of the SSE request, only the same
+	// identity can resume it (see handleLastEventID)
+	identity := ""
+	if msg.Request != nil {
+		identity = Identity(msg.Request)
+	}
+
+	// the browser reconnecting with Last-Event-ID resumes its detached session
+	// right away, with no fresh session spawned for the connection
+	if msg.Request != nil && m.resumeOnConnect(msg, identity) {
+		return
+	}
+
+	if m.maxSessions > 0 && m.attachedSessions() >= m.maxSessions {
+		m.Log().Warning("SSE connect: %s rejected, session limit reached (%d)", msg.ID, m.maxSessions)
+		data, _ := json.Marshal(struct {
+			Reason string `json:"Reason"`
+		}{Reason: fmt.Sprintf("session limit reached (%d)", m.maxSessions)})
+		m.SendAlias(msg.ID, sse.Message{Event: "session_closed", Data: data})
+		m.SendExitMeta(msg.ID, errors.New("session limit reached"))
+		return
+	}
+
 	sessionID := generateSessionID()
-	sessionName := gen.Atom("observer_session_" + sessionID)
+	sessionName := sessionProcessName(sessionID)
 
 	m.Log().Info("SSE connect: %s → %s", msg.ID, sessionName)
 
 	opts := gen.ProcessOptions{
 		LinkParent: true,
 	}
-	_, err := m.SpawnRegister(sessionName, factory_session, opts, sessionID, msg.ID)
+	pid, err := m.SpawnRegister(sessionName, factory_session, opts, sessionID, msg.ID, m.options, identity)
 	if err != nil {
 		m.Log().Error("failed to spawn session %s: %s", sessionName, err)
 		return
 	}
+	m.MonitorPID(pid)
+	m.sessions[pid] = sessionSummary{ID: sessionID, Identity: identity, Started: time.Now()}
+	m.connections[msg.ID] = sessionName
+	m.identities[msg.ID] = identity
+}
+
+// attachedSessions returns the number of sessions with SSE connection. Detached
+// sessions waiting for resumption within the grace period are not counted,
+// so the reconnecting browser is never rejected by the limit.
+func (m *mgr) attachedSessions() int {
+	n := 0
+	for _, summary := range m.sessions {
+		if summary.Detached == false {
+			n++
+		}
+	}
+	return n
+}
+
+// resumeOnConnect resumes the session given in the Last-Event-ID header of the SSE request
+func (m *mgr) resumeOnConnect(msg sse.MessageConnect, identity string) bool {
+	if m.options.GracePeriod <= 0 {
+		return false
+	}
+	id, lastEventID, ok := parseEventID(msg.Request.Header.Get("Last-Event-ID"))
+	if ok == false || m.resumable(id, identity) == false {
+		return false
+	}
+	previous := sessionProcessName(id)
+	resume := messageResume{
+		SSE:         msg.ID,
+		LastEventID: lastEventID,
+		Identity:    identity,
+	}
+	if err := m.Send(previous, resume); err != nil {
+		return false
+	}
+	m.connections[msg.ID] = previous
+	m.identities[msg.ID] = identity
+	m.Log().Info("SSE connect: %s → %s (resuming after event %d)", msg.ID, previous, lastEventID)
+	return true
+}
+
+// resumable checks if the session exists and belongs to the identity
+func (m *mgr) resumable(id string, identity string) bool {
+	for _, summary := range m.sessions {
+		if summary.ID == id {
+			return summary.Identity == identity
+		}
+	}
+	return false
+}
+
+// parseEventID parses the event ID "<sessionID>-<counter>"
+func parseEventID(eventID string) (string, int64, bool) {
+	id, counter, found := strings.Cut(eventID, "-")
+	if found == false {
+		return "", 0, false
+	}
+	n, err := strconv.ParseInt(counter, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return id, n, true
+}
+
+// handleLastEventID resumes the session the browser was attached to before reconnecting.
+// Event IDs are "<sessionID>-<counter>". If that session is gone (grace period expired),
+// the fresh session spawned on connect just keeps serving the connection. The session
+// rejects the resumption if the identity of the new connection differs from its own.
+func (m *mgr) handleLastEventID(msg sse.MessageLastEventID) {
+	if m.options.GracePeriod <= 0 {
+		return
+	}
+	id, lastEventID, ok := parseEventID(msg.LastEventID)
+	if ok == false {
+		m.Log().Debug("SSE last event ID %q: unknown format", msg.LastEventID)
+		return
+	}
+
+	fresh, exist := m.connections[msg.ID]
+	if exist == false {
+		return
+	}
+	previous := sessionProcessName(id)
+	if previous == fresh {
+		// already resumed on connect
+		return
+	}
+	if m.resumable(id, m.identities[msg.ID]) == false {
+		m.Log().Debug("SSE reconnect %s: session %s can not be resumed, keeping %s", msg.ID, previous, fresh)
+		return
+	}
+
+	resume := messageResume{
+		SSE:         msg.ID,
+		LastEventID: lastEventID,
+		Fresh:       fresh,
+		Identity:    m.identities[msg.ID],
+	}
+	if err := m.Send(previous, resume); err != nil {
+		m.Log().Debug("SSE reconnect %s: session %s is gone, keeping %s", msg.ID, previous, fresh)
+		return
+	}
+	m.connections[msg.ID] = previous
+	m.Log().Info("SSE reconnect: %s → %s (resuming after event %d)", msg.ID, previous, lastEventID)
 }
 
 func (m *mgr) Terminate(reason error) {
 	m.Log().Info("session manager terminated: %s", reason)
 }
 
+func sessionProcessName(id string) gen.Atom {
+	return gen.Atom("observer_session_" + id)
+}
+
 func generateSessionID() string {
 	b := make([]byte, 16)
 	rand.Read(b)
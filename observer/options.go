@@ -1,11 +1,23 @@
 package observer
 
-import "ergo.services/ergo/gen"
+import (
+	"crypto/x509"
+	"net/http"
+	"time"
+
+	"ergo.services/ergo/gen"
+)
 
 const (
 	DefaultPort       uint16 = 9911
 	defaultPoolSize   int    = 10
 	defaultCallTimeout int   = 5 // seconds
+
+	defaultSessionGracePeriod = 30 * time.Second
+	defaultHistoryDuration    = time.Hour
+
+	defaultRecordMaxSize  int64 = 64 << 20 // 64MB
+	defaultRecordMaxFiles int   = 20
 )
 
 type Options struct {
@@ -15,6 +27,121 @@ type Options struct {
 	// Port for HTTP listener. Default: 9911
 	Port uint16
 
+	// PathPrefix serves Observer under the URL prefix (e.g. "/observer")
+	// instead of the root. Useful behind an ingress. Default: "" (root)
+	PathPrefix string
+
+	// Mux to attach Observer handlers to (at PathPrefix) instead of starting
+	// its own web server. Allows to share a port with Radar or an admin server.
+	// Host, Port, CertManager and ClientCAs are ignored in this case.
+	// Requires PathPrefix, Observer doesn't claim the root of a shared mux.
+	// The handler is registered once, restarts of Observer replace it in place.
+	Mux *http.ServeMux
+
+	// CertManager for TLS. nil = plain HTTP
+	CertManager gen.CertManager
+
+	// ClientCAs enables mutual TLS: only clients presenting a certificate signed
+	// by one of these CAs can connect. The certificate subject (CommonName) becomes
+	// the identity of the request unless Auth provides one. If Auth has Users or Tokens,
+	// they are required as well. Requires CertManager.
+	ClientCAs *x509.CertPool
+
+	// Auth configures authentication of the dashboard and its /api endpoints.
+	// Empty = no auth
+	Auth AuthOptions
+
+	// ReadOnly disables actions that change the state of the observed node
+	// (kill, send, app_stop, set_log_level, etc). Inspecting is still allowed.
+	ReadOnly bool
+
+	// AllowedActions whitelist. nil/empty = all actions enabled (respecting ReadOnly)
+	AllowedActions []string
+
+	// SessionGracePeriod keeps a session with its subscriptions after the SSE
+	// connection is lost, so the browser reconnecting with Last-Event-ID resumes it
+	// and gets the missed events. Default: 30s. Negative value disables resumption.
+	SessionGracePeriod time.Duration
+
+	// RecordDir enables recording of sessions: every SSE event a session receives
+	// is written to a timestamped file in this directory. Recording is toggled with
+	// POST /api/record {"enable": true}. Recordings are listed at GET /api/v1/recordings,
+	// downloaded at GET /api/v1/recordings/<name> and replayed in the dashboard by
+	// opening /replay/<name>?speed=2. Empty = disabled
+	RecordDir string
+
+	// RecordAll records every session from the start. Requires RecordDir.
+	RecordAll bool
+
+	// RecordMaxSize limits the size of a recording file (bytes). The recording
+	// continues in a new file once it is reached. Default: 64MB
+	RecordMaxSize int64
+
+	// RecordMaxFiles limits the number of recording files kept in RecordDir,
+	// the oldest ones are removed. Default: 20
+	RecordMaxFiles int
+
+	// MaxSessions limits the number of concurrent dashboard sessions. 0 = unlimited
+	MaxSessions int
+
+	// SessionIdleTimeout closes a session with no API requests (subscribe, actions, etc)
+	// for this long. 0 = disabled
+	SessionIdleTimeout time.Duration
+
+	// MaxSubscriptions limits the number of subscriptions per session. 0 = unlimited
+	MaxSubscriptions int
+
+	// EventCoalesceWindow delays inspect events for this long and sends only the
+	// latest event of each subscription within the window (log entries are never
+	// coalesced). Reduces traffic for slow clients. 0 = send every event immediately
+	EventCoalesceWindow time.Duration
+
+	// MaxPendingEvents is the SSE connection backlog above which the session skips
+	// frames until the client catches up, and sends the "degraded" event to the
+	// frontend. The backlog is checked when the coalesced events are flushed, so
+	// EventCoalesceWindow defaults to 200ms if not set. 0 = no limit
+	MaxPendingEvents int64
+
+	// Alerts configures alert rules evaluated on the node. Fired alerts are sent
+	// to every session as the "alert" SSE event, listed at GET /api/v1/alerts and
+	// optionally POSTed to the webhook. No rules = disabled
+	Alerts AlertOptions
+
+	// History configures the rolling buffer of the node metrics. It is sent on
+	// node_info subscription (the "node_history" SSE event) and served at
+	// GET /api/v1/history for API clients, the bundled dashboard doesn't chart it
+	// yet. Default: 1h at 10s resolution
+	History HistoryOptions
+
+	// ClusterInterval is the refresh interval of the cluster overview (all the known
+	// nodes with their stats). Default: 5s
+	ClusterInterval time.Duration
+
+	// ClusterMailboxThreshold is the mailbox depth of a process to be shown
+	// as a hotspot in the cluster overview. Default: 100
+	ClusterMailboxThreshold uint64
+
+	// ClusterConnect allows the cluster overview to connect to the nodes discovered
+	// by the registrar. By default only the connected nodes are watched, the others
+	// are listed as unreachable.
+	ClusterConnect bool
+
+	// CronActions are the actions of the node's cron jobs (the same as in gen.CronJob)
+	// that can be run on demand from the dashboard. gen.Cron doesn't expose
+	// the job actions, other jobs can only be enabled and disabled.
+	// The map is owned by the application and must follow its jobs: unlike
+	// the MCP cron tools, Observer doesn't add jobs, so it has no actions
+	// of its own to validate against the scheduler.
+	CronActions map[gen.Atom]gen.CronAction
+
+	// LogBacklog is the number of recent log messages (info and above) of the node kept
+	// in memory. They are sent to the dashboard on log subscription, so it shows recent
+	// errors right away, and served at GET /api/v1/log/download. Debug and trace
+	// messages are never kept: a subscription to these levels gets them live only,
+	// its backlog has the info and above ones. Default: 1000.
+	// Negative value disables the backlog
+	LogBacklog int
+
 	// PoolSize is the number of POST request workers. Default: 10
 	PoolSize int
 
@@ -0,0 +1,79 @@
+package observer
+
+import "sort"
+
+// readActions do not change the state of the observed node
+var readActions = []string{
+	"inspect",
+	"goroutines",
+	"heap",
+	"cpu_profile",
+	"message_types",
+	"message_type_info",
+}
+
+// writeActions change the state of the observed node
+var writeActions = []string{
+	"send",
+	"call",
+	"send_exit",
+	"kill",
+	"set_log_level",
+	"app_start",
+	"app_stop",
+	"app_unload",
+	"set_process_send_priority",
+	"set_process_compression",
+	"set_process_compression_type",
+	"set_process_compression_level",
+	"set_process_compression_threshold",
+	"set_process_keep_network_order",
+	"set_process_important_delivery",
+	"set_meta_send_priority",
+	"cron_run",
+	"cron_enable",
+	"cron_disable",
+	"session_terminate", // admin API: DELETE /api/v1/sessions/<id>
+	"session_list",      // admin API: GET /api/v1/sessions, exposes identities and addresses
+	"record",            // writes the session events to Options.RecordDir
+}
+
+// capabilities describes the actions permitted by Options.ReadOnly and Options.AllowedActions.
+// Advertised to the frontend in the "connected" SSE event so it can hide disallowed controls.
+type capabilities struct {
+	ReadOnly bool     `json:"ReadOnly"`
+	Actions  []string `json:"Actions"`
+
+	allowed map[string]bool
+}
+
+func newCapabilities(options Options) capabilities {
+	c := capabilities{
+		ReadOnly: options.ReadOnly,
+		allowed:  make(map[string]bool),
+	}
+
+	candidates := readActions
+	if options.ReadOnly == false {
+		candidates = append(append([]string{}, readActions...), writeActions...)
+	}
+
+	whitelist := make(map[string]bool)
+	for _, action := range options.AllowedActions {
+		whitelist[action] = true
+	}
+
+	for _, action := range candidates {
+		if len(whitelist) > 0 && whitelist[action] == false {
+			continue
+		}
+		c.allowed[action] = true
+		c.Actions = append(c.Actions, action)
+	}
+	sort.Strings(c.Actions)
+	return c
+}
+
+func (c capabilities) allow(action string) bool {
+	return c.allowed[action]
+}
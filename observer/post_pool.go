@@ -14,6 +14,8 @@ type postPool struct {
 }
 
 func (p *postPool) Init(args ...any) (act.PoolOptions, error) {
+	options := args[0].(Options)
+
 	poolSize := int64(defaultPoolSize)
 	if v, exist := p.Env("pool_size"); exist {
 		if ps, ok := v.(int); ok && ps > 0 {
@@ -24,5 +26,6 @@ func (p *postPool) Init(args ...any) (act.PoolOptions, error) {
 	return act.PoolOptions{
 		WorkerFactory: factory_post_worker,
 		PoolSize:      poolSize,
+		WorkerArgs:    []any{newCapabilities(options)},
 	}, nil
 }
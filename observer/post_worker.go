@@ -2,11 +2,13 @@ package observer
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
 
 	"ergo.services/ergo/act"
+	"ergo.services/ergo/app/system/inspect"
 	"ergo.services/ergo/gen"
 )
 
@@ -20,9 +22,11 @@ func factory_post_worker() gen.ProcessBehavior {
 // Done() called automatically by act.WebWorker.
 type postWorker struct {
 	act.WebWorker
+	capabilities capabilities
 }
 
 func (w *postWorker) Init(args ...any) error {
+	w.capabilities = args[0].(capabilities)
 	return nil
 }
 
@@ -39,21 +43,27 @@ func (w *postWorker) HandlePost(from gen.PID, writer http.ResponseWriter, reques
 		return nil
 	}
 
-	sessionName := gen.Atom("observer_session_" + sessionID)
+	sessionName := sessionProcessName(sessionID)
 	path := request.URL.Path
 
+	if sessionID == replaySessionID {
+		// the dashboard is showing a recording, there is no session behind it
+		writeJSON(writer, http.StatusOK, apiResponse{OK: true})
+		return nil
+	}
+
 	switch {
-	case path == "/api/subscribe" || path == "/api/unsubscribe" || path == "/api/switch":
-		w.handleCommand(writer, sessionName, path, body)
+	case path == "/api/subscribe" || path == "/api/unsubscribe" || path == "/api/switch" || path == "/api/record":
+		w.handleCommand(writer, sessionName, request, path, body)
 	case strings.HasPrefix(path, "/api/do/"):
-		w.handleAction(writer, sessionName, strings.TrimPrefix(path, "/api/do/"), body)
+		w.handleAction(writer, sessionName, request, strings.TrimPrefix(path, "/api/do/"), body)
 	default:
 		writeJSON(writer, http.StatusNotFound, apiResponse{Error: "not found"})
 	}
 	return nil
 }
 
-func (w *postWorker) handleCommand(writer http.ResponseWriter, session gen.Atom, path string, body []byte) {
+func (w *postWorker) handleCommand(writer http.ResponseWriter, session gen.Atom, request *http.Request, path string, body []byte) {
 	var req struct {
 		Type string         `json:"type"`
 		Args map[string]any `json:"args"`
@@ -63,6 +73,8 @@ func (w *postWorker) handleCommand(writer http.ResponseWriter, session gen.Atom,
 		Host   string `json:"host"`
 		Port   int    `json:"port"`
 		TLS    bool   `json:"tls"`
+		// record options
+		Enable bool `json:"enable"`
 	}
 	if err := json.Unmarshal(body, &req); err != nil {
 		writeJSON(writer, http.StatusBadRequest, apiResponse{Error: "invalid JSON"})
@@ -70,15 +82,30 @@ func (w *postWorker) handleCommand(writer http.ResponseWriter, session gen.Atom,
 	}
 
 	cmd := commandRequest{
-		Command: strings.TrimPrefix(path, "/api/"),
-		Type:    req.Type,
-		Args:    req.Args,
+		Command:    strings.TrimPrefix(path, "/api/"),
+		Type:       req.Type,
+		Args:       req.Args,
+		Identity:   Identity(request),
+		RemoteAddr: request.RemoteAddr,
 	}
-	if cmd.Command == "switch" {
-		if cmd.Args == nil {
-			cmd.Args = make(map[string]any)
+	if cmd.Args == nil {
+		cmd.Args = make(map[string]any)
+	}
+	switch cmd.Command {
+	case "record":
+		if w.capabilities.allow("record") == false {
+			w.Log().Warning("rejected command record by %q: not permitted", cmd.Identity)
+			writeJSON(writer, http.StatusForbidden, apiResponse{Error: "action record is not permitted"})
+			return
 		}
+		cmd.Args["enable"] = req.Enable
+	case "switch":
 		cmd.Args["node"] = req.Node
+	default:
+		// subscribe/unsubscribe on another node than the primary one
+		cmd.Node = req.Node
+	}
+	if cmd.Command != "unsubscribe" {
 		if req.Cookie != "" {
 			cmd.Args["Cookie"] = req.Cookie
 		}
@@ -111,11 +138,17 @@ func (w *postWorker) handleCommand(writer http.ResponseWriter, session gen.Atom,
 	writeJSON(writer, http.StatusOK, resp)
 }
 
-func (w *postWorker) handleAction(writer http.ResponseWriter, session gen.Atom, action string, body []byte) {
+func (w *postWorker) handleAction(writer http.ResponseWriter, session gen.Atom, request *http.Request, action string, body []byte) {
+	identity := Identity(request)
 	if action == "" {
 		writeJSON(writer, http.StatusBadRequest, apiResponse{Error: "missing action"})
 		return
 	}
+	if w.capabilities.allow(action) == false {
+		w.Log().Warning("rejected action %s by %q: not permitted", action, identity)
+		writeJSON(writer, http.StatusForbidden, apiResponse{Error: "action " + action + " is not permitted"})
+		return
+	}
 
 	var args map[string]any
 	if err := json.Unmarshal(body, &args); err != nil {
@@ -123,11 +156,19 @@ func (w *postWorker) handleAction(writer http.ResponseWriter, session gen.Atom,
 		return
 	}
 
-	result, err := w.CallWithTimeout(session, actionRequest{Action: action, Args: args}, defaultCallTimeout)
+	result, err := w.CallWithTimeout(session, actionRequest{
+		Action:     action,
+		Args:       args,
+		Identity:   identity,
+		RemoteAddr: request.RemoteAddr,
+	}, defaultCallTimeout)
 	if err != nil {
 		writeJSON(writer, http.StatusInternalServerError, apiResponse{Error: err.Error()})
 		return
 	}
+	if call, ok := result.(typedCall); ok {
+		result = w.doTypedCall(call)
+	}
 
 	resp, ok := result.(apiResponse)
 	if ok == false {
@@ -141,6 +182,60 @@ func (w *postWorker) handleAction(writer http.ResponseWriter, session gen.Atom,
 	writeJSON(writer, http.StatusOK, resp)
 }
 
+// HandleMessage does the blocking part of the work of rest and cluster
+func (w *postWorker) HandleMessage(from gen.PID, message any) error {
+	switch m := message.(type) {
+	case messageRestInspect:
+		w.handleRestInspect(m)
+	case messageClusterWatch:
+		w.handleClusterWatch(m)
+	default:
+		w.Log().Warning("unknown message from %s: %#v", from, message)
+	}
+	return nil
+}
+
+func (w *postWorker) handleRestInspect(m messageRestInspect) {
+	fail := func(status int, err string) {
+		writeJSON(m.Request.Response, status, apiResponse{Error: err})
+		m.Request.Done()
+	}
+
+	query := m.Request.Request.URL.Query()
+	args := routeArgs(query.Get("cookie"), query.Get("host"), query.Get("port"), query.Get("tls"))
+	if err := connectNode(w.Node(), m.Node, args); err != nil {
+		fail(http.StatusBadGateway, fmt.Sprintf("connect to %s: %s", m.Node, err))
+		return
+	}
+
+	inspectReq, err := buildRestRequest(w, m.Node, m.Path, query)
+	if err != nil {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	inspectPID := gen.ProcessID{Name: inspect.Name, Node: m.Node}
+	result, err := w.CallWithTimeout(inspectPID, inspectReq, defaultCallTimeout)
+	if err != nil {
+		fail(http.StatusBadGateway, fmt.Sprintf("inspect call: %s", err))
+		return
+	}
+	event, err := extractEvent(result)
+	if err != nil {
+		fail(http.StatusBadGateway, fmt.Sprintf("inspect response: %s", err))
+		return
+	}
+
+	inspected := messageRestInspected{Request: m.Request, Event: event}
+	if info, ok := result.(inspect.ResponseInspectNode); ok {
+		info.Event = gen.Event{}
+		inspected.Info = info
+	}
+	if err := w.Send(restName, inspected); err != nil {
+		fail(http.StatusServiceUnavailable, err.Error())
+	}
+}
+
 func (w *postWorker) Terminate(reason error) {}
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
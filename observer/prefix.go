@@ -0,0 +1,103 @@
+package observer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// normalizePrefix turns "observer", "/observer/" etc into "/observer". Root is "".
+func normalizePrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return "/" + prefix
+}
+
+// rewriteAssets adapts the embedded frontend bundle to be served under the prefix.
+// The bundle is built for the root path: it loads assets by absolute paths,
+// uses window.location.origin as the base URL for /sse and /api/,
+// and mounts the router at "/". Returns the rewritten files (pre-compressed)
+// keyed by path, and the .html patterns not found in the bundle. Returns error
+// if any of the .js patterns is not found (the bundle was rebuilt differently).
+func rewriteAssets(fsys fs.FS, prefix string) (map[string][]byte, []string, error) {
+	replacements := map[string][]string{
+		".html": {
+			`href="/`, `href="` + prefix + `/`,
+			`src="/`, `src="` + prefix + `/`,
+		},
+		".js": {
+			"window.location.origin", "(window.location.origin+`" + prefix + "`)",
+			"basename:t=`/`", "basename:t=`" + prefix + "`",
+		},
+	}
+
+	files := make(map[string][]byte)
+	applied := make(map[string]int)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(name, ".gz") == false {
+			return err
+		}
+		pairs, ok := replacements[path.Ext(strings.TrimSuffix(name, ".gz"))]
+		if ok == false {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			return err
+		}
+
+		s := string(content)
+		for i := 0; i < len(pairs); i += 2 {
+			applied[pairs[i]] += strings.Count(s, pairs[i])
+			s = strings.ReplaceAll(s, pairs[i], pairs[i+1])
+		}
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		files[name] = buf.Bytes()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the router and the API base URL break silently without the .js patterns
+	var missing, required []string
+	for ext, pairs := range replacements {
+		for i := 0; i < len(pairs); i += 2 {
+			if applied[pairs[i]] > 0 {
+				continue
+			}
+			missing = append(missing, pairs[i])
+			if ext == ".js" {
+				required = append(required, pairs[i])
+			}
+		}
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		return nil, nil, fmt.Errorf("unable to adapt the frontend bundle to prefix %s: patterns %q not found", prefix, required)
+	}
+	sort.Strings(missing)
+	return files, missing, nil
+}
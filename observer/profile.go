@@ -0,0 +1,138 @@
+package observer
+
+import (
+	"bytes"
+	"fmt"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	"ergo.services/ergo/app/system/inspect"
+	"ergo.services/ergo/gen"
+	pprofprofile "github.com/google/pprof/profile"
+)
+
+const (
+	defaultCPUProfileDuration = 5 * time.Second
+	maxCPUProfileDuration     = 30 * time.Second
+)
+
+// foldedProfile is the profile in the folded stacks format ("root;caller;leaf value"
+// per line, sorted), the input of flame graph renderers.
+type foldedProfile struct {
+	Sample string `json:"Sample"` // cpu (nanoseconds), inuse or alloc (bytes)
+	Total  int64  `json:"Total"`
+	Folded string `json:"Folded"`
+}
+
+// messageCPUProfile sent to the session by the profiling goroutine,
+// the result is delivered to the browser as the "cpu_profile" SSE event
+type messageCPUProfile struct {
+	Profile foldedProfile
+	Error   string
+}
+
+func formatFolded(sample string, stacks map[string]int64) foldedProfile {
+	p := foldedProfile{Sample: sample}
+	lines := make([]string, 0, len(stacks))
+	for stack, value := range stacks {
+		if value <= 0 {
+			continue
+		}
+		p.Total += value
+		lines = append(lines, fmt.Sprintf("%s %d", stack, value))
+	}
+	sort.Strings(lines)
+	p.Folded = strings.Join(lines, "\n")
+	return p
+}
+
+// startCPUProfile profiles the node running Observer for the duration. The profile
+// is collected in a goroutine, the result is sent to the process as messageCPUProfile.
+// Only one CPU profile can be collected at a time.
+func startCPUProfile(node gen.Node, to gen.PID, duration time.Duration) error {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return err
+	}
+	go func() {
+		time.Sleep(duration)
+		pprof.StopCPUProfile()
+
+		var m messageCPUProfile
+		stacks, err := foldPprof(buf.Bytes())
+		if err != nil {
+			m.Error = fmt.Sprintf("unable to parse CPU profile: %s", err)
+		} else {
+			m.Profile = formatFolded("cpu", stacks)
+		}
+		node.Send(to, m)
+	}()
+	return nil
+}
+
+// foldHeap builds the folded stacks of the heap profile returned by system_inspect.
+// Sample selects the value: "inuse" (default) or "alloc" bytes.
+func foldHeap(heap inspect.ResponseDoHeapProfile, sample string) foldedProfile {
+	if sample != "alloc" {
+		sample = "inuse"
+	}
+	stacks := make(map[string]int64)
+	for _, r := range heap.Records {
+		value := r.InuseBytes
+		if sample == "alloc" {
+			value = r.AllocBytes
+		}
+		stacks[foldStack(r.Stack)] += value
+	}
+	return formatFolded(sample, stacks)
+}
+
+// foldStack joins the frames (leaf first) from the root to the leaf
+func foldStack(frames []string) string {
+	folded := make([]string, len(frames))
+	for i, frame := range frames {
+		// ";" separates frames in the folded format
+		folded[len(frames)-1-i] = strings.ReplaceAll(frame, ";", ":")
+	}
+	return strings.Join(folded, ";")
+}
+
+// foldPprof returns the folded stacks of the CPU profile (nanoseconds)
+func foldPprof(data []byte) (map[string]int64, error) {
+	prof, err := pprofprofile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// the "cpu" sample value, the last one if not found
+	index := len(prof.SampleType) - 1
+	for i, st := range prof.SampleType {
+		if st.Type == "cpu" {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("profile has no sample values")
+	}
+
+	stacks := make(map[string]int64)
+	for _, sample := range prof.Sample {
+		var frames []string // leaf first
+		for _, loc := range sample.Location {
+			// inlined callee first
+			for _, line := range loc.Line {
+				if line.Function == nil {
+					continue
+				}
+				frames = append(frames, line.Function.Name)
+			}
+		}
+		if len(frames) == 0 {
+			continue
+		}
+		stacks[foldStack(frames)] += sample.Value[index]
+	}
+	return stacks, nil
+}
@@ -0,0 +1,323 @@
+package observer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	recordExt    = ".jsonl"
+	replayCookie = "observer_replay"
+
+	// replaySessionID is advertised in the "connected" event of a replayed
+	// recording. POST requests with this session ID are accepted and ignored.
+	replaySessionID = "replay"
+)
+
+// recordEntry is a line of the recording file
+type recordEntry struct {
+	T     int64           `json:"t"` // milliseconds since the recording started
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type recordingInfo struct {
+	Name     string    `json:"Name"`
+	Size     int64     `json:"Size"`
+	Modified time.Time `json:"Modified"`
+}
+
+// recorder writes every SSE event of a session to a file
+type recorder struct {
+	name    string
+	part    int // number of the file within the recording (rotation)
+	file    *os.File
+	enc     *json.Encoder
+	size    int64
+	started time.Time
+}
+
+type recordLimits struct {
+	maxSize  int64 // see Options.RecordMaxSize
+	maxFiles int   // see Options.RecordMaxFiles
+}
+
+func createRecorder(dir string, sessionID string, part int, limits recordLimits) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	// keep room for the new file
+	if err := pruneRecordings(dir, limits.maxFiles-1); err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	name := fmt.Sprintf("observer-%s-%s%s", started.Format("20060102-150405"), sessionID[:8], recordExt)
+	if part > 0 {
+		name = fmt.Sprintf("observer-%s-%s-%d%s", started.Format("20060102-150405"), sessionID[:8], part, recordExt)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	r := &recorder{
+		name:    name,
+		part:    part,
+		file:    file,
+		started: started,
+	}
+	r.enc = json.NewEncoder(r)
+	return r, nil
+}
+
+// Write implements io.Writer for the encoder, counting the file size
+func (r *recorder) Write(p []byte) (int, error) {
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// pruneRecordings removes the oldest recordings in dir, keeping the given number of them
+func pruneRecordings(dir string, keep int) error {
+	if keep < 0 {
+		keep = 0
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var list []recordingInfo
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), recordExt) == false {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		list = append(list, recordingInfo{Name: entry.Name(), Modified: info.ModTime()})
+	}
+	if len(list) <= keep {
+		return nil
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Modified.Before(list[j].Modified)
+	})
+	for _, rec := range list[:len(list)-keep] {
+		if err := os.Remove(filepath.Join(dir, rec.Name)); err != nil && os.IsNotExist(err) == false {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *recorder) write(event string, data []byte) error {
+	return r.enc.Encode(recordEntry{
+		T:     time.Since(r.started).Milliseconds(),
+		Event: event,
+		Data:  data,
+	})
+}
+
+func (r *recorder) close() error {
+	return r.file.Close()
+}
+
+// recordingPath validates the recording name and returns its path
+func recordingPath(dir string, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasSuffix(name, recordExt) == false {
+		return "", fmt.Errorf("invalid recording name %q", name)
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("recording %q not found", name)
+	}
+	return path, nil
+}
+
+// recordingsHandler serves GET /api/v1/recordings (list) and
+// GET /api/v1/recordings/<name> (download).
+func recordingsHandler(dir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, apiResponse{Error: "method not allowed"})
+			return
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/v1/recordings"), "/")
+		if name == "" {
+			entries, err := os.ReadDir(dir)
+			if err != nil && os.IsNotExist(err) == false {
+				writeJSON(w, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+				return
+			}
+			list := []recordingInfo{}
+			for _, entry := range entries {
+				if entry.IsDir() || strings.HasSuffix(entry.Name(), recordExt) == false {
+					continue
+				}
+				info, err := entry.Info()
+				if err != nil {
+					continue
+				}
+				list = append(list, recordingInfo{Name: entry.Name(), Size: info.Size(), Modified: info.ModTime()})
+			}
+			sort.Slice(list, func(i, j int) bool {
+				return list[i].Modified.After(list[j].Modified)
+			})
+			writeJSON(w, http.StatusOK, apiResponse{OK: true, Data: list})
+			return
+		}
+
+		path, err := recordingPath(dir, name)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, apiResponse{Error: err.Error()})
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+			return
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		http.ServeContent(w, r, name, info.ModTime(), file)
+	}
+}
+
+// replayStartHandler serves GET /replay/<name>?speed=N. It marks the browser for
+// replay with a cookie and redirects to the dashboard, which then receives
+// the recording from /sse instead of the live session.
+func replayStartHandler(dir string, prefix string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/replay/")
+		if _, err := recordingPath(dir, name); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		speed := 1.0
+		if v, err := strconv.ParseFloat(r.URL.Query().Get("speed"), 64); err == nil && v > 0 {
+			speed = v
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     replayCookie,
+			Value:    url.QueryEscape(fmt.Sprintf("%s|%g", name, speed)),
+			Path:     prefix + "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+		http.Redirect(w, r, prefix+"/", http.StatusFound)
+	}
+}
+
+// replayHandler streams the recording selected by the replay cookie in SSE format,
+// keeping the recorded pace divided by the speed. Requests without the cookie go
+// to the live SSE handler. The cookie is cleared right away, so reloading the page
+// returns to the live dashboard.
+func replayHandler(dir string, prefix string, live http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(replayCookie)
+		if err != nil || cookie.Value == "" {
+			live.ServeHTTP(w, r)
+			return
+		}
+		value, _ := url.QueryUnescape(cookie.Value)
+		name, speedStr, _ := strings.Cut(value, "|")
+		speed, err := strconv.ParseFloat(speedStr, 64)
+		if err != nil || speed <= 0 {
+			speed = 1
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:   replayCookie,
+			Path:   prefix + "/",
+			MaxAge: -1,
+		})
+
+		path, err := recordingPath(dir, name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+
+		flusher, ok := w.(http.Flusher)
+		if ok == false {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 1<<20), 64<<20)
+		started := time.Now()
+		id := 0
+		for scanner.Scan() {
+			var entry recordEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			at := time.Duration(float64(entry.T)/speed) * time.Millisecond
+			if wait := at - time.Since(started); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			data := []byte(entry.Data)
+			if entry.Event == "connected" {
+				data = replayConnected(data)
+			}
+			id++
+			fmt.Fprintf(w, "id: replay-%d\nevent: %s\ndata: %s\n\n", id, entry.Event, data)
+			flusher.Flush()
+		}
+
+		fmt.Fprintf(w, "event: replay_end\ndata: {\"Name\":%q}\n\n", name)
+		flusher.Flush()
+
+		// keep the connection open, so the browser does not reconnect
+		// to the live session and the final state stays on the screen
+		<-r.Context().Done()
+	}
+}
+
+// replayConnected makes the recorded "connected" event refer to the replay session,
+// which accepts and ignores API requests and has no actions.
+func replayConnected(data []byte) []byte {
+	var intro map[string]any
+	if err := json.Unmarshal(data, &intro); err != nil {
+		return data
+	}
+	intro["SessionID"] = replaySessionID
+	intro["Capabilities"] = capabilities{ReadOnly: true, Actions: []string{}}
+	intro["Replay"] = true
+	out, err := json.Marshal(intro)
+	if err != nil {
+		return data
+	}
+	return out
+}
@@ -0,0 +1,427 @@
+package observer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ergo.services/ergo/act"
+	"ergo.services/ergo/app/system/inspect"
+	"ergo.services/ergo/gen"
+	"ergo.services/ergo/meta"
+)
+
+// restWaitTimeout limits waiting for the first event of the inspector
+const restWaitTimeout = 5 * time.Second
+
+func factory_rest() gen.ProcessBehavior {
+	return &rest{}
+}
+
+// rest serves the headless JSON API (GET /api/v1/*) for scripts.
+// No SSE session: each request calls system_inspect once and returns the first
+// data the inspector produces (buffered or the next tick), then unsubscribes.
+// Requests are completed asynchronously (Done is called once data arrives),
+// so it is an act.Actor rather than act.WebWorker. The blocking part (connecting
+// to the node, the inspect call) is done by the post pool workers, so a slow node
+// doesn't hold the other requests (see messageRestInspect).
+type rest struct {
+	act.Actor
+
+	capabilities capabilities
+	pending      map[string]*restPending // event key → requests waiting for data
+	seq          uint64                  // of the pending entries, matches their timeouts
+}
+
+type restPending struct {
+	seq      uint64
+	event    gen.Event
+	info     any // extra info from the inspect response (node_info only)
+	requests []meta.MessageWebRequest
+}
+
+type messageRestTimeout struct {
+	Key string
+	Seq uint64 // the entry may be resolved and created again for the same key
+}
+
+// messageRestInspect sent by rest to the post pool: a worker connects to the node,
+// makes the inspect call and sends messageRestInspected back (or completes
+// the request with the error)
+type messageRestInspect struct {
+	Request meta.MessageWebRequest
+	Node    gen.Atom
+	Path    string
+}
+
+type messageRestInspected struct {
+	Request meta.MessageWebRequest
+	Event   gen.Event
+	Info    any // node_info only
+}
+
+func (r *rest) Init(args ...any) error {
+	r.Log().SetLogger("default")
+	r.capabilities = newCapabilities(args[0].(Options))
+	r.pending = make(map[string]*restPending)
+	return nil
+}
+
+func (r *rest) HandleMessage(from gen.PID, message any) error {
+	switch m := message.(type) {
+	case meta.MessageWebRequest:
+		r.handleRequest(m)
+
+	case messageRestInspected:
+		r.await(m.Request, m.Event, m.Info)
+
+	case messageRestTimeout:
+		p, exist := r.pending[m.Key]
+		if exist == false || p.seq != m.Seq {
+			return nil
+		}
+		delete(r.pending, m.Key)
+		r.DemonitorEvent(p.event)
+		for _, req := range p.requests {
+			writeJSON(req.Response, http.StatusGatewayTimeout, apiResponse{Error: "no data from inspector"})
+			req.Done()
+		}
+
+	case gen.MessageDownEvent:
+		key := m.Event.String()
+		p, exist := r.pending[key]
+		if exist == false {
+			return nil
+		}
+		delete(r.pending, key)
+		for _, req := range p.requests {
+			writeJSON(req.Response, http.StatusNotFound, apiResponse{Error: "terminated"})
+			req.Done()
+		}
+
+	default:
+		r.Log().Warning("unknown message from %s: %#v", from, message)
+	}
+	return nil
+}
+
+func (r *rest) HandleEvent(message gen.MessageEvent) error {
+	key := message.Event.String()
+	p, exist := r.pending[key]
+	if exist == false {
+		return nil
+	}
+	delete(r.pending, key)
+	r.DemonitorEvent(p.event)
+	r.reply(p, message.Message)
+	return nil
+}
+
+func (r *rest) handleRequest(m meta.MessageWebRequest) {
+	path := strings.TrimPrefix(m.Request.URL.Path, "/api/v1/")
+	if path == "sessions" || strings.HasPrefix(path, "sessions/") {
+		r.handleSessions(m, strings.TrimPrefix(strings.TrimPrefix(path, "sessions"), "/"))
+		m.Done()
+		return
+	}
+
+	if m.Request.Method != http.MethodGet {
+		writeJSON(m.Response, http.StatusMethodNotAllowed, apiResponse{Error: "method not allowed"})
+		m.Done()
+		return
+	}
+
+	if path == "alerts" {
+		r.handleAlerts(m)
+		m.Done()
+		return
+	}
+
+	if path == "log/download" {
+		r.handleLogDownload(m)
+		m.Done()
+		return
+	}
+
+	if path == "history" {
+		r.handleHistory(m)
+		m.Done()
+		return
+	}
+
+	query := m.Request.URL.Query()
+	node := r.Node().Name()
+	if v := query.Get("node"); v != "" {
+		node = gen.Atom(v)
+	}
+	if path == "log" && node == r.Node().Name() && r.handleLogBacklog(m) {
+		m.Done()
+		return
+	}
+
+	if err := r.Send(poolName, messageRestInspect{Request: m, Node: node, Path: path}); err != nil {
+		writeJSON(m.Response, http.StatusServiceUnavailable, apiResponse{Error: err.Error()})
+		m.Done()
+	}
+}
+
+// await completes the request with the first data of the inspect event
+// (buffered or the next tick)
+func (r *rest) await(m meta.MessageWebRequest, event gen.Event, info any) {
+	key := event.String()
+	if p, exist := r.pending[key]; exist {
+		// the same data is already awaited
+		p.requests = append(p.requests, m)
+		return
+	}
+
+	p := &restPending{
+		event:    event,
+		info:     info,
+		requests: []meta.MessageWebRequest{m},
+	}
+
+	buffered, err := r.MonitorEvent(event)
+	if err != nil {
+		writeJSON(m.Response, http.StatusBadGateway, apiResponse{Error: fmt.Sprintf("monitor: %s", err)})
+		m.Done()
+		return
+	}
+	if len(buffered) > 0 {
+		r.DemonitorEvent(event)
+		r.reply(p, buffered[len(buffered)-1].Message)
+		return
+	}
+
+	r.pending[key] = p
+	r.seq++
+	p.seq = r.seq
+	r.SendAfter(r.PID(), messageRestTimeout{Key: key, Seq: p.seq}, restWaitTimeout)
+}
+
+// handleSessions serves the admin API: GET /api/v1/sessions lists active sessions,
+// DELETE /api/v1/sessions/<id> terminates the session.
+func (r *rest) handleSessions(m meta.MessageWebRequest, id string) {
+	switch {
+	case m.Request.Method == http.MethodGet && id == "":
+		if r.capabilities.allow("session_list") == false {
+			writeJSON(m.Response, http.StatusForbidden, apiResponse{Error: "action session_list is not permitted"})
+			return
+		}
+		result, err := r.Call(mgrName, requestSessionList{})
+		if err != nil {
+			writeJSON(m.Response, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(m.Response, http.StatusOK, apiResponse{OK: true, Data: result})
+
+	case m.Request.Method == http.MethodDelete && id != "":
+		if r.capabilities.allow("session_terminate") == false {
+			writeJSON(m.Response, http.StatusForbidden, apiResponse{Error: "action session_terminate is not permitted"})
+			return
+		}
+		reason := "terminated by admin"
+		if identity := Identity(m.Request); identity != "" {
+			reason += " " + identity
+		}
+		result, err := r.Call(mgrName, requestSessionClose{ID: id, Reason: reason})
+		if err != nil {
+			writeJSON(m.Response, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+			return
+		}
+		if e, ok := result.(error); ok {
+			writeJSON(m.Response, http.StatusNotFound, apiResponse{Error: e.Error()})
+			return
+		}
+		writeJSON(m.Response, http.StatusOK, apiResponse{OK: true})
+
+	default:
+		writeJSON(m.Response, http.StatusMethodNotAllowed, apiResponse{Error: "method not allowed"})
+	}
+}
+
+// handleAlerts serves GET /api/v1/alerts, the alerts currently firing
+func (r *rest) handleAlerts(m meta.MessageWebRequest) {
+	result, err := r.Call(alertsName, requestAlertList{})
+	if err == gen.ErrProcessUnknown {
+		writeJSON(m.Response, http.StatusNotFound, apiResponse{Error: "alerting is disabled (no rules in Options.Alerts)"})
+		return
+	}
+	if err != nil {
+		writeJSON(m.Response, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(m.Response, http.StatusOK, apiResponse{OK: true, Data: result})
+}
+
+// handleLogBacklog serves GET /api/v1/log of the node running Observer from
+// the log backlog, so a quiet node doesn't leave the request waiting for the next
+// entry. The query parameters limit and level apply, as well as the filter ones
+// of /api/v1/log/download. Returns false if the backlog is disabled.
+func (r *rest) handleLogBacklog(m meta.MessageWebRequest) bool {
+	query := m.Request.URL.Query()
+	filter, err := parseLogFilter(query.Get, splitList(query.Get("level")))
+	if err != nil {
+		writeJSON(m.Response, http.StatusBadRequest, apiResponse{Error: err.Error()})
+		return true
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	result, err := r.Call(logsName, requestLogBacklog{Filter: filter, Limit: limit})
+	if err != nil {
+		return false
+	}
+	entries, _ := result.([]logEntry)
+	if entries == nil {
+		entries = []logEntry{}
+	}
+	writeJSON(m.Response, http.StatusOK, apiResponse{OK: true, Data: struct {
+		Node    gen.Atom   `json:"Node"`
+		Entries []logEntry `json:"Entries"`
+		Backlog bool       `json:"Backlog"`
+	}{
+		Node:    r.Node().Name(),
+		Entries: entries,
+		Backlog: true,
+	}})
+	return true
+}
+
+// handleHistory serves GET /api/v1/history, the metrics history of the local node
+func (r *rest) handleHistory(m meta.MessageWebRequest) {
+	result, err := r.Call(historyName, requestHistory{})
+	if err == gen.ErrProcessUnknown {
+		writeJSON(m.Response, http.StatusNotFound, apiResponse{Error: "history is disabled (Options.History.Duration is negative)"})
+		return
+	}
+	if err != nil {
+		writeJSON(m.Response, http.StatusInternalServerError, apiResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(m.Response, http.StatusOK, apiResponse{OK: true, Data: result})
+}
+
+func (r *rest) reply(p *restPending, data any) {
+	var resp apiResponse
+	resp.OK = true
+	resp.Data = data
+	if p.info != nil {
+		resp.Data = struct {
+			Info  any `json:"Info"`
+			Stats any `json:"Stats"`
+		}{Info: p.info, Stats: data}
+	}
+	for _, req := range p.requests {
+		writeJSON(req.Response, http.StatusOK, resp)
+		req.Done()
+	}
+}
+
+// buildRestRequest maps the REST path to the inspect request.
+func buildRestRequest(process gen.Process, node gen.Atom, path string, query url.Values) (any, error) {
+	get := query.Get
+	atoi := func(name string, def int) int {
+		if v, err := strconv.Atoi(query.Get(name)); err == nil && v > 0 {
+			return v
+		}
+		return def
+	}
+
+	switch {
+	case path == "node":
+		return inspect.RequestInspectNode{}, nil
+
+	case path == "processes":
+		return inspect.RequestInspectProcessList{
+			Start:       atoi("start", 1000),
+			Limit:       atoi("limit", 500),
+			Name:        get("name"),
+			Behavior:    get("behavior"),
+			Application: get("application"),
+			State:       get("state"),
+		}, nil
+
+	case strings.HasPrefix(path, "process/"):
+		creation, err := nodeCreation(process, node)
+		if err != nil {
+			return nil, err
+		}
+		pid, err := str2pid(node, creation, strings.TrimPrefix(path, "process/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid: %s", err)
+		}
+		return inspect.RequestInspectProcess{PID: pid}, nil
+
+	case path == "applications":
+		return inspect.RequestInspectApplicationList{}, nil
+
+	case path == "events":
+		return inspect.RequestInspectEventList{
+			Limit: atoi("limit", 500),
+			Name:  get("name"),
+		}, nil
+
+	case path == "connections":
+		return inspect.RequestInspectConnectionList{
+			Limit: atoi("limit", 100),
+			Name:  get("name"),
+		}, nil
+
+	case path == "log":
+		req := inspect.RequestInspectLog{Limit: atoi("limit", 0)}
+		if levels := get("level"); levels != "" {
+			for _, l := range strings.Split(levels, ",") {
+				req.Levels = append(req.Levels, parseLogLevel(strings.TrimSpace(l)))
+			}
+		}
+		return req, nil
+	}
+	return nil, fmt.Errorf("unknown endpoint: /api/v1/%s", path)
+}
+
+// nodeCreation returns the creation of the node, needed to build its PIDs.
+func nodeCreation(process gen.Process, node gen.Atom) (int64, error) {
+	if node == process.Node().Name() {
+		return process.Node().Creation(), nil
+	}
+	inspectPID := gen.ProcessID{Name: inspect.Name, Node: node}
+	result, err := process.CallWithTimeout(inspectPID, inspect.RequestInspectNode{}, defaultCallTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("inspect %s: %s", node, err)
+	}
+	info, ok := result.(inspect.ResponseInspectNode)
+	if ok == false {
+		return 0, fmt.Errorf("unexpected response from remote inspect")
+	}
+	return info.Creation, nil
+}
+
+func (r *rest) Terminate(reason error) {
+	for _, p := range r.pending {
+		for _, req := range p.requests {
+			writeJSON(req.Response, http.StatusServiceUnavailable, apiResponse{Error: "terminated"})
+			req.Done()
+		}
+	}
+	r.Log().Info("REST API terminated: %s", reason)
+}
+
+// routeArgs builds connection args for connectNode from the query parameters
+func routeArgs(cookie, host, port, tls string) map[string]any {
+	args := make(map[string]any)
+	if cookie != "" {
+		args["Cookie"] = cookie
+	}
+	if host != "" {
+		args["Host"] = host
+	}
+	if p, err := strconv.Atoi(port); err == nil && p > 0 {
+		args["Port"] = float64(p)
+	}
+	if tls == "true" || tls == "1" {
+		args["TLS"] = true
+	}
+	return args
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"ergo.services/ergo/act"
 	"ergo.services/ergo/app/system/inspect"
@@ -20,32 +21,97 @@ type session struct {
 	act.Actor
 
 	id            string
+	identity      string // authenticated user, bound on SSE connect or the first API request
 	sseAlias      gen.Alias
-	node          gen.Atom
+	capabilities  capabilities
+	node          gen.Atom // primary observed node (actions, switch)
 	creation      int64
-	subscriptions map[string]gen.Event // eventKey → gen.Event (for DemonitorEvent)
-	subIndex      map[string]string    // lookupKey → eventKey (for unsubscribe lookup)
+	creations     map[gen.Atom]int64    // observed nodes → creation (to parse PIDs/aliases)
+	subscriptions map[string]gen.Event  // eventKey → gen.Event (for DemonitorEvent)
+	subIndex      map[string]string     // node/lookupKey → eventKey (for unsubscribe lookup)
+	logFilters    map[string]*logFilter // eventKey → server-side filter of the log subscription
+	heatmaps      map[string]*heatmap   // eventKey → deltas of the process_heatmap subscription
 	eventCounter  int64
+
+	grace      time.Duration // how long to wait for resumption after SSE disconnect
+	detachedAt time.Time     // non-zero while SSE is disconnected
+	replay     []replayEvent // recent events to replay on resumption
+
+	recordDir    string
+	recordLimits recordLimits
+	recorder     *recorder // non-nil while recording
+
+	started          time.Time
+	lastActive       time.Time // last API request, for the idle timeout
+	remoteAddr       string    // of the last API request
+	idleTimeout      time.Duration
+	maxSubscriptions int
+
+	// throttling of inspect events (see throttle.go)
+	coalesceWindow time.Duration
+	maxPending     int64
+	coalesced      map[string]throttledEvent // event key → latest event in the window
+	coalesceOrder  []string                  // keys in the order of arrival
+	queued         []throttledEvent          // events never coalesced (log)
+	flushScheduled bool
+	degraded       bool
+	dropped        int64
+}
+
+// replayBufferSize limits the number of events kept for replay on resumption
+const replayBufferSize = 512
+
+type replayEvent struct {
+	ID      int64
+	Message sse.Message
 }
 
 func (s *session) Init(args ...any) error {
 	s.id = args[0].(string)
 	s.sseAlias = args[1].(gen.Alias)
+	options := args[2].(sessionOptions)
+	s.identity = args[3].(string)
+	s.capabilities = options.Capabilities
+	s.grace = options.GracePeriod
+	s.recordDir = options.RecordDir
+	s.recordLimits = options.RecordLimits
+	s.idleTimeout = options.IdleTimeout
+	s.maxSubscriptions = options.MaxSubscriptions
+	s.coalesceWindow = options.CoalesceWindow
+	s.maxPending = options.MaxPending
+	s.coalesced = make(map[string]throttledEvent)
+	s.started = time.Now()
+	s.lastActive = s.started
 	s.node = s.Node().Name()
 	s.creation = s.Node().Creation()
+	s.creations = map[gen.Atom]int64{s.node: s.creation}
 	s.subscriptions = make(map[string]gen.Event)
 	s.subIndex = make(map[string]string)
+	s.logFilters = make(map[string]*logFilter)
+	s.heatmaps = make(map[string]*heatmap)
 
 	s.Log().SetLogger("default")
 
-	// link to SSE connection meta — session dies when SSE dies
-	if err := s.LinkAlias(s.sseAlias); err != nil {
-		s.Log().Error("session %s: LinkAlias failed: %s", s.id, err)
+	if options.RecordAll && s.recordDir != "" {
+		if err := s.startRecording(); err != nil {
+			s.Log().Error("session %s: unable to start recording: %s", s.id, err)
+		}
+	}
+
+	// monitor SSE connection meta — session waits for resumption when SSE dies
+	if err := s.MonitorAlias(s.sseAlias); err != nil {
+		s.Log().Error("session %s: MonitorAlias failed: %s", s.id, err)
 		return err
 	}
 
 	// send "connected" event to browser with session ID
-	s.sendConnectedEvent()
+	s.sendConnectedEvent(false)
+	s.subscribeAlerts()
+
+	if s.idleTimeout > 0 {
+		s.SendAfter(s.PID(), messageSessionIdle{}, s.idleTimeout)
+	}
+	s.reportStatus()
 
 	s.Log().Info("session %s started, SSE: %s", s.id, s.sseAlias)
 	return nil
@@ -53,13 +119,82 @@ func (s *session) Init(args ...any) error {
 
 func (s *session) HandleMessage(from gen.PID, message any) error {
 	switch m := message.(type) {
+	case gen.MessageDownAlias:
+		if m.Alias != s.sseAlias || s.detachedAt.IsZero() == false {
+			return nil
+		}
+		if s.grace <= 0 {
+			return gen.TerminateReasonNormal
+		}
+		// keep subscriptions for a while, the browser reconnects with Last-Event-ID
+		s.detachedAt = time.Now()
+		s.SendAfter(s.PID(), messageSessionExpire{DetachedAt: s.detachedAt}, s.grace)
+		s.Log().Info("session %s: SSE disconnected, waiting %s for resumption", s.id, s.grace)
+		s.reportStatus()
+
+	case messageSessionExpire:
+		if s.detachedAt.Equal(m.DetachedAt) == false {
+			// resumed
+			return nil
+		}
+		s.Log().Info("session %s: not resumed within %s", s.id, s.grace)
+		return gen.TerminateReasonNormal
+
+	case messageResume:
+		s.resume(m)
+
+	case messageSessionFlush:
+		s.flushSSE()
+
+	case messageCPUProfile:
+		var data []byte
+		if m.Error != "" {
+			data, _ = json.Marshal(apiResponse{Error: m.Error})
+		} else {
+			data, _ = json.Marshal(apiResponse{OK: true, Data: m.Profile})
+		}
+		s.sendSSE("cpu_profile", data)
+
+	case messageSessionIdle:
+		idle := time.Since(s.lastActive)
+		if idle < s.idleTimeout {
+			s.SendAfter(s.PID(), messageSessionIdle{}, s.idleTimeout-idle)
+			return nil
+		}
+		s.Log().Info("session %s: idle for %s", s.id, idle.Truncate(time.Second))
+		s.closeSSE("idle timeout")
+		return gen.TerminateReasonNormal
+
+	case messageSessionClose:
+		s.Log().Info("session %s: closed: %s", s.id, m.Reason)
+		s.closeSSE(m.Reason)
+		return gen.TerminateReasonNormal
+
+	case messageReplaced:
+		// an existing session took over our SSE connection
+		s.Log().Info("session %s: replaced by resumed session", s.id)
+		return gen.TerminateReasonNormal
+
+	case gen.MessageDownNode:
+		// the node may come back with another creation
+		delete(s.creations, m.Name)
+
 	case gen.MessageDownEvent:
 		// inspect event source terminated (e.g. observed process died)
 		key := m.Event.String()
+		if s.subIndex[alertsLookupKey] == key {
+			delete(s.subscriptions, key)
+			delete(s.subIndex, alertsLookupKey)
+			return nil
+		}
 		if _, exist := s.subscriptions[key]; exist {
 			// build terminated payload in the same format as inspect sends
 			// so frontend handles it with the same code path
 			eventType := inspectEventToSSEType(m.Event.Name)
+			if _, exist := s.heatmaps[key]; exist {
+				eventType = "process_heatmap"
+				delete(s.heatmaps, key)
+			}
 			var payload any
 
 			// find lookup key to get the ID
@@ -75,9 +210,9 @@ func (s *session) HandleMessage(from gen.PID, message any) error {
 			case "process_info":
 				// extract PID from "process_info:pid=<CRC.0.1006>"
 				pid := extractIDFromLookupKey(lookupKey)
-				p, _ := str2pid(s.node, s.creation, pid)
+				p, _ := str2pid(m.Event.Node, s.creations[m.Event.Node], pid)
 				payload = inspect.MessageInspectProcess{
-					Node: s.node,
+					Node: m.Event.Node,
 					Info: gen.ProcessInfo{
 						PID:   p,
 						State: gen.ProcessStateTerminated,
@@ -85,9 +220,9 @@ func (s *session) HandleMessage(from gen.PID, message any) error {
 				}
 			case "meta_info":
 				alias := extractIDFromLookupKey(lookupKey)
-				a, _ := str2alias(s.node, s.creation, alias)
+				a, _ := str2alias(m.Event.Node, s.creations[m.Event.Node], alias)
 				payload = inspect.MessageInspectMeta{
-					Node: s.node,
+					Node: m.Event.Node,
 					Info: gen.MetaInfo{
 						ID:    a,
 						State: gen.MetaStateTerminated,
@@ -96,7 +231,7 @@ func (s *session) HandleMessage(from gen.PID, message any) error {
 			case "connection_info":
 				node := extractIDFromLookupKey(lookupKey)
 				payload = inspect.MessageInspectConnection{
-					Node:         s.node,
+					Node:         m.Event.Node,
 					Disconnected: true,
 					Info: gen.RemoteNodeInfo{
 						Node: gen.Atom(node),
@@ -109,12 +244,8 @@ func (s *session) HandleMessage(from gen.PID, message any) error {
 			}
 
 			data, _ := json.Marshal(payload)
-			s.eventCounter++
-			s.SendAlias(s.sseAlias, sse.Message{
-				Event: eventType,
-				Data:  data,
-				MsgID: fmt.Sprintf("%d", s.eventCounter),
-			})
+			s.discardThrottled(key)
+			s.sendSSE(eventType, tagNode(data, m.Event.Node))
 
 			delete(s.subscriptions, key)
 			for k, v := range s.subIndex {
@@ -135,13 +266,90 @@ func (s *session) HandleMessage(from gen.PID, message any) error {
 func (s *session) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
 	switch r := request.(type) {
 	case commandRequest:
-		return s.handleCommand(r)
+		if err := s.checkIdentity(r.Identity); err != nil {
+			return apiResponse{Error: err.Error()}, nil
+		}
+		s.touch(r.RemoteAddr)
+		result, err := s.handleCommand(r)
+		s.reportStatus()
+		return result, err
 	case actionRequest:
-		return s.handleAction(r)
+		if err := s.checkIdentity(r.Identity); err != nil {
+			return apiResponse{Error: err.Error()}, nil
+		}
+		s.touch(r.RemoteAddr)
+		result, err := s.handleAction(r)
+		s.reportStatus()
+		return result, err
 	}
 	return nil, gen.ErrUnsupported
 }
 
+// touch marks the session active for the idle timeout
+func (s *session) touch(remoteAddr string) {
+	s.lastActive = time.Now()
+	if remoteAddr != "" {
+		s.remoteAddr = remoteAddr
+	}
+}
+
+// subscriptionCount returns the number of subscriptions made by the browser
+func (s *session) subscriptionCount() int {
+	n := len(s.subIndex)
+	if _, exist := s.subIndex["registrar_event"]; exist {
+		n--
+	}
+	if _, exist := s.subIndex[alertsLookupKey]; exist {
+		n--
+	}
+	return n
+}
+
+// reportStatus sends the session summary to mgr for the admin API
+func (s *session) reportStatus() {
+	s.Send(mgrName, sessionSummary{
+		ID:            s.id,
+		Identity:      s.identity,
+		RemoteAddr:    s.remoteAddr,
+		Node:          s.node,
+		Subscriptions: s.subscriptionCount(),
+		Started:       s.started,
+		LastActive:    s.lastActive,
+		Recording:     s.recorder != nil,
+		Detached:      s.detachedAt.IsZero() == false,
+	})
+}
+
+// closeSSE tells the browser why the session is closed and closes the SSE connection.
+// The browser reconnects and gets a new session.
+func (s *session) closeSSE(reason string) {
+	if s.detachedAt.IsZero() == false {
+		return
+	}
+	data, _ := json.Marshal(struct {
+		Reason string `json:"Reason"`
+	}{Reason: reason})
+	s.sendSSE("session_closed", data)
+	s.SendExitMeta(s.sseAlias, errors.New(reason))
+}
+
+// checkIdentity binds the session to the identity of the first API request.
+// Requests of other users are rejected, so the session ID can not be reused by them.
+func (s *session) checkIdentity(identity string) error {
+	if s.identity == "" {
+		if identity != "" {
+			s.identity = identity
+			s.Log().Info("session %s: bound to %q", s.id, identity)
+		}
+		return nil
+	}
+	if s.identity != identity {
+		s.Log().Warning("session %s: rejected request of %q (session belongs to %q)", s.id, identity, s.identity)
+		return errors.New("session belongs to another user")
+	}
+	return nil
+}
+
 func (s *session) HandleEvent(message gen.MessageEvent) error {
 	key := message.Event.String()
 	if _, exist := s.subscriptions[key]; exist == false {
@@ -156,38 +364,185 @@ func (s *session) HandleEvent(message gen.MessageEvent) error {
 		return nil
 	}
 
+	if s.subIndex[alertsLookupKey] == key {
+		s.sendAlert(message.Message)
+		return nil
+	}
+
 	data, err := json.Marshal(message.Message)
 	if err != nil {
 		s.Log().Error("session %s: marshal event %s: %s", s.id, key, err)
 		return nil
 	}
 
-	eventType := inspectEventToSSEType(message.Event.Name)
-	s.eventCounter++
-
-	sseMsg := sse.Message{
-		Event: eventType,
-		Data:  data,
-		MsgID: fmt.Sprintf("%d", s.eventCounter),
+	if filter, exist := s.logFilters[key]; exist {
+		if data = filter.filterEvent(s.Node(), data); data == nil {
+			return nil
+		}
 	}
-	if err := s.SendAlias(s.sseAlias, sseMsg); err != nil {
-		s.Log().Error("session %s: SendAlias failed: %s", s.id, err)
+
+	if h, exist := s.heatmaps[key]; exist {
+		if data = h.update(data); data != nil {
+			s.throttleSSE(key, "process_heatmap", tagNode(data, message.Event.Node))
+		}
+		return nil
 	}
+
+	s.throttleSSE(key, inspectEventToSSEType(message.Event.Name), tagNode(data, message.Event.Node))
 	return nil
 }
 
 func (s *session) Terminate(reason error) {
+	s.stopRecording()
 	s.Log().Info("session %s terminated: %s", s.id, reason)
 }
 
-// handleCommand processes subscribe/unsubscribe/switch
+func (s *session) startRecording() error {
+	if s.recorder != nil {
+		return nil
+	}
+	r, err := createRecorder(s.recordDir, s.id, 0, s.recordLimits)
+	if err != nil {
+		return err
+	}
+	s.recorder = r
+	s.Log().Info("session %s: recording to %s", s.id, r.name)
+	return nil
+}
+
+// rotateRecording continues the recording in the next file once the current
+// one reached Options.RecordMaxSize
+func (s *session) rotateRecording() {
+	part := s.recorder.part + 1
+	s.stopRecording()
+	r, err := createRecorder(s.recordDir, s.id, part, s.recordLimits)
+	if err != nil {
+		s.Log().Error("session %s: unable to rotate recording: %s", s.id, err)
+		return
+	}
+	s.recorder = r
+	s.Log().Info("session %s: recording continues in %s", s.id, r.name)
+}
+
+func (s *session) stopRecording() {
+	if s.recorder == nil {
+		return
+	}
+	if err := s.recorder.close(); err != nil {
+		s.Log().Error("session %s: closing recording %s: %s", s.id, s.recorder.name, err)
+	}
+	s.Log().Info("session %s: recording %s stopped", s.id, s.recorder.name)
+	s.recorder = nil
+}
+
+// doRecord starts or stops recording of the SSE events the session receives
+func (s *session) doRecord(enable bool) (any, error) {
+	if s.recordDir == "" {
+		return apiResponse{Error: "recording is disabled (Options.RecordDir is not set)"}, nil
+	}
+	if enable == false {
+		s.stopRecording()
+		return apiResponse{OK: true}, nil
+	}
+	if err := s.startRecording(); err != nil {
+		return apiResponse{Error: fmt.Sprintf("start recording: %s", err)}, nil
+	}
+	return apiResponse{OK: true, Data: s.recorder.name}, nil
+}
+
+// sendSSE sends the event to the browser and keeps it in the replay buffer.
+// While detached (SSE disconnected, waiting for resumption) events are only buffered.
+// MsgID carries the session ID, so the browser's Last-Event-ID identifies the session on reconnect.
+func (s *session) sendSSE(event string, data []byte) {
+	if s.recorder != nil {
+		if err := s.recorder.write(event, data); err != nil {
+			s.Log().Error("session %s: recording failed: %s", s.id, err)
+			s.stopRecording()
+		} else if s.recorder.size >= s.recordLimits.maxSize {
+			s.rotateRecording()
+		}
+	}
+
+	s.eventCounter++
+	msg := sse.Message{
+		Event: event,
+		Data:  data,
+		MsgID: fmt.Sprintf("%s-%d", s.id, s.eventCounter),
+	}
+
+	if s.grace > 0 {
+		if len(s.replay) == replayBufferSize {
+			s.replay = s.replay[1:]
+		}
+		s.replay = append(s.replay, replayEvent{ID: s.eventCounter, Message: msg})
+	}
+
+	if s.detachedAt.IsZero() == false {
+		return
+	}
+	if err := s.SendAlias(s.sseAlias, msg); err != nil {
+		s.Log().Error("session %s: SendAlias failed: %s", s.id, err)
+	}
+}
+
+// resume attaches the session to the new SSE connection, replays the events
+// the browser missed and sends "connected" so the frontend picks up the session ID.
+func (s *session) resume(m messageResume) {
+	if m.SSE == s.sseAlias {
+		return
+	}
+	if m.Identity != s.identity {
+		s.Log().Warning("session %s: rejected resumption by %q (session belongs to %q)",
+			s.id, m.Identity, s.identity)
+		return
+	}
+	if s.detachedAt.IsZero() {
+		// reconnected before the old connection was detected as closed
+		s.DemonitorAlias(s.sseAlias)
+	}
+	if err := s.MonitorAlias(m.SSE); err != nil {
+		s.Log().Error("session %s: resume failed, MonitorAlias: %s", s.id, err)
+		return
+	}
+	s.sseAlias = m.SSE
+	s.detachedAt = time.Time{}
+	if m.Fresh != "" {
+		s.Send(m.Fresh, messageReplaced{})
+	}
+
+	replayed := 0
+	for _, ev := range s.replay {
+		if ev.ID <= m.LastEventID {
+			continue
+		}
+		s.SendAlias(s.sseAlias, ev.Message)
+		replayed++
+	}
+	s.sendConnectedEvent(true)
+	s.reportStatus()
+	s.Log().Info("session %s resumed, SSE: %s, replayed %d events", s.id, s.sseAlias, replayed)
+}
+
+// handleCommand processes subscribe/unsubscribe/switch/record
 func (s *session) handleCommand(cmd commandRequest) (any, error) {
+	// subscriptions are on the primary node unless another one is given
+	node := s.node
+	if cmd.Node != "" {
+		node = gen.Atom(cmd.Node)
+	}
+
 	switch cmd.Command {
 	case "subscribe":
-		return s.doSubscribe(cmd.Type, cmd.Args)
+		return s.doSubscribe(node, cmd.Type, cmd.Args)
 	case "unsubscribe":
-		s.doUnsubscribe(cmd.Type, cmd.Args)
+		s.doUnsubscribe(node, cmd.Type, cmd.Args)
 		return apiResponse{OK: true}, nil
+	case "record":
+		if s.capabilities.allow("record") == false {
+			return apiResponse{Error: "action record is not permitted"}, nil
+		}
+		enable, _ := cmd.Args["enable"].(bool)
+		return s.doRecord(enable)
 	case "switch":
 		node, _ := cmd.Args["node"].(string)
 		if node == "" {
@@ -200,11 +555,31 @@ func (s *session) handleCommand(cmd commandRequest) (any, error) {
 
 // handleAction processes do/* commands by forwarding to system_inspect on the observed node
 func (s *session) handleAction(req actionRequest) (any, error) {
+	switch req.Action {
+	case "message_types", "message_type_info":
+		return s.handleTypedAction(req.Action, req.Args), nil
+	case "cron_run", "cron_enable", "cron_disable":
+		return s.doCronAction(req), nil
+	case "cpu_profile":
+		return s.doCPUProfile(req), nil
+	case "send", "call":
+		// typed message from the composer, sent by the session itself
+		if name, _ := req.Args["type"].(string); name != "" || req.Action == "call" {
+			s.Log().Info("session %s: action %s %s on %s by %s", s.id, req.Action, name, s.node, identityOrAnonymous(req.Identity))
+			if req.Action == "call" {
+				return s.prepareTypedCall(req.Args), nil
+			}
+			return s.handleTypedAction(req.Action, req.Args), nil
+		}
+	}
+
 	inspectReq, err := s.buildActionRequest(req.Action, req.Args)
 	if err != nil {
 		return apiResponse{Error: err.Error()}, nil
 	}
 
+	s.Log().Info("session %s: action %s on %s by %s: %v", s.id, req.Action, s.node, identityOrAnonymous(req.Identity), req.Args)
+
 	inspectPID := gen.ProcessID{Name: inspect.Name, Node: s.node}
 	result, err := s.CallWithTimeout(inspectPID, inspectReq, defaultCallTimeout)
 	if err != nil {
@@ -229,11 +604,68 @@ func (s *session) handleAction(req actionRequest) (any, error) {
 		if r.Error != nil {
 			return apiResponse{Error: r.Error.Error()}, nil
 		}
+		if folded, _ := req.Args["folded"].(bool); folded {
+			sample, _ := req.Args["sample"].(string)
+			return apiResponse{OK: true, Data: foldHeap(r, sample)}, nil
+		}
 		return apiResponse{OK: true, Data: r}, nil
 	}
 	return apiResponse{OK: true}, nil
 }
 
+// doCPUProfile starts CPU profiling of the node running Observer. It takes a while,
+// so the result comes as the "cpu_profile" SSE event.
+func (s *session) doCPUProfile(req actionRequest) apiResponse {
+	if s.node != s.Node().Name() {
+		return apiResponse{Error: fmt.Sprintf("cpu_profile: %s is a remote node, CPU profile is available for the node running Observer (%s) only", s.node, s.Node().Name())}
+	}
+	duration := defaultCPUProfileDuration
+	if v, ok := req.Args["duration"].(float64); ok && v > 0 {
+		duration = time.Duration(v * float64(time.Second))
+	}
+	if duration > maxCPUProfileDuration {
+		duration = maxCPUProfileDuration
+	}
+	if err := startCPUProfile(s.Node(), s.PID(), duration); err != nil {
+		return apiResponse{Error: fmt.Sprintf("cpu_profile: %s", err)}
+	}
+	s.Log().Info("session %s: CPU profile for %s by %s", s.id, duration, identityOrAnonymous(req.Identity))
+	return apiResponse{OK: true, Data: struct {
+		Duration time.Duration `json:"Duration"`
+	}{Duration: duration}}
+}
+
+// doCronAction runs, enables or disables the cron job of the node running Observer
+func (s *session) doCronAction(req actionRequest) apiResponse {
+	if s.node != s.Node().Name() {
+		return apiResponse{Error: "cron is available for the node running Observer only"}
+	}
+	job, _ := req.Args["job"].(string)
+	if job == "" {
+		return apiResponse{Error: "job is required"}
+	}
+	s.Log().Info("session %s: action %s %s by %s", s.id, req.Action, job, identityOrAnonymous(req.Identity))
+
+	result, err := s.Call(cronName, requestCronAction{
+		Action: strings.TrimPrefix(req.Action, "cron_"),
+		Job:    gen.Atom(job),
+	})
+	if err != nil {
+		return apiResponse{Error: fmt.Sprintf("action %s: %s", req.Action, err)}
+	}
+	if e, ok := result.(error); ok {
+		return apiResponse{Error: e.Error()}
+	}
+	return apiResponse{OK: true}
+}
+
+func identityOrAnonymous(identity string) string {
+	if identity == "" {
+		return "anonymous"
+	}
+	return identity
+}
+
 func (s *session) buildActionRequest(action string, args map[string]any) (any, error) {
 	switch action {
 	case "send":
@@ -553,15 +985,51 @@ func actionError(result any) error {
 	return nil
 }
 
-// doSubscribe calls system_inspect to start inspector, then MonitorEvent
-func (s *session) doSubscribe(subType string, args map[string]any) (any, error) {
-	inspectReq, err := s.buildInspectRequest(subType, args)
+// doSubscribe calls system_inspect to start inspector, then MonitorEvent.
+// The node may differ from the primary one, so a session can observe several nodes at once.
+func (s *session) doSubscribe(node gen.Atom, subType string, args map[string]any) (any, error) {
+	switch subType {
+	case "cluster_overview":
+		return s.subscribeObserverEvent(subType, clusterEvent)
+	case "cron":
+		if node != s.Node().Name() {
+			return apiResponse{Error: "cron is available for the node running Observer only"}, nil
+		}
+		return s.subscribeObserverEvent(subType, cronEvent)
+	}
+
+	var filter *logFilter
+	if subType == "log" {
+		f, err := parseLogFilter(argsGetter(args), logLevels(args))
+		if err != nil {
+			return apiResponse{Error: err.Error()}, nil
+		}
+		if f.Application != "" && node != s.Node().Name() {
+			return apiResponse{Error: "application filter is available for the node running Observer only"}, nil
+		}
+		filter = f
+	}
+	var hm *heatmap
+	if subType == "process_heatmap" {
+		h, err := newHeatmap(args)
+		if err != nil {
+			return apiResponse{Error: err.Error()}, nil
+		}
+		hm = h
+	}
+
+	creation, err := s.observe(node, args)
+	if err != nil {
+		return apiResponse{Error: err.Error()}, nil
+	}
+
+	inspectReq, err := s.buildInspectRequest(node, creation, subType, args)
 	if err != nil {
 		return apiResponse{Error: err.Error()}, nil
 	}
 
 	// call system_inspect to start/reuse the inspector child
-	inspectPID := gen.ProcessID{Name: inspect.Name, Node: s.node}
+	inspectPID := gen.ProcessID{Name: inspect.Name, Node: node}
 	result, err := s.CallWithTimeout(inspectPID, inspectReq, defaultCallTimeout)
 	if err != nil {
 		return apiResponse{Error: fmt.Sprintf("inspect call: %s", err)}, nil
@@ -576,21 +1044,35 @@ func (s *session) doSubscribe(subType string, args map[string]any) (any, error)
 	eventKey := event.String()
 
 	// for log subscriptions, auto-unsubscribe previous if filter changed
-	lookupKey := subLookupKey(subType, args)
+	lookupKey := nodeLookupKey(node, subType, args)
 	if oldEventKey, exist := s.subIndex[lookupKey]; exist && oldEventKey != eventKey {
 		if ev, ok := s.subscriptions[oldEventKey]; ok {
 			s.DemonitorEvent(ev)
 			delete(s.subscriptions, oldEventKey)
 		}
+		s.discardThrottled(oldEventKey)
 		delete(s.subIndex, lookupKey)
+		delete(s.logFilters, oldEventKey)
+		delete(s.heatmaps, oldEventKey)
 		s.Log().Info("session %s: auto-unsubscribed %s (replaced)", s.id, oldEventKey)
 	}
 
-	// dedup by event key
+	// dedup by event key (the filter of the log subscription may change)
 	if _, exist := s.subscriptions[eventKey]; exist {
+		if filter != nil {
+			s.setLogFilter(eventKey, filter)
+		}
+		if h, exist := s.heatmaps[eventKey]; exist && hm != nil {
+			// keep the counters, take the new top and sort
+			h.top, h.sort = hm.top, hm.sort
+		}
 		return apiResponse{OK: true}, nil
 	}
 
+	if s.maxSubscriptions > 0 && s.subscriptionCount() >= s.maxSubscriptions {
+		return apiResponse{Error: fmt.Sprintf("subscription limit reached (%d)", s.maxSubscriptions)}, nil
+	}
+
 	// monitor the inspect event
 	if _, err := s.MonitorEvent(event); err != nil {
 		return apiResponse{Error: fmt.Sprintf("monitor: %s", err)}, nil
@@ -598,17 +1080,55 @@ func (s *session) doSubscribe(subType string, args map[string]any) (any, error)
 
 	s.subscriptions[eventKey] = event
 	s.subIndex[lookupKey] = eventKey
+	if filter != nil {
+		s.setLogFilter(eventKey, filter)
+	}
+	if hm != nil {
+		s.heatmaps[eventKey] = hm
+	}
 	s.Log().Info("session %s: subscribed %s [%s] → %s (total subs: %d)", s.id, subType, lookupKey, eventKey, len(s.subscriptions))
 
 	// send initial data from inspect response for types that carry extra info
-	s.sendInitialData(subType, result)
+	s.sendInitialData(node, subType, result)
+	if filter != nil {
+		s.sendLogBacklog(node, filter, args)
+	}
 
 	return apiResponse{OK: true}, nil
 }
 
+// subscribeObserverEvent monitors the event published by the Observer process
+// (cluster overview, cron). It is not bound to the observed node and survives switching.
+func (s *session) subscribeObserverEvent(subType string, name gen.Atom) (any, error) {
+	event := gen.Event{Name: name, Node: s.Node().Name()}
+	if _, exist := s.subscriptions[event.String()]; exist {
+		return apiResponse{OK: true}, nil
+	}
+	if s.maxSubscriptions > 0 && s.subscriptionCount() >= s.maxSubscriptions {
+		return apiResponse{Error: fmt.Sprintf("subscription limit reached (%d)", s.maxSubscriptions)}, nil
+	}
+	buffered, err := s.MonitorEvent(event)
+	if err != nil {
+		return apiResponse{Error: fmt.Sprintf("monitor: %s", err)}, nil
+	}
+	s.subscriptions[event.String()] = event
+	s.subIndex[subType] = event.String()
+	s.Log().Info("session %s: subscribed %s", s.id, subType)
+
+	// the latest state, the next one comes within the refresh interval
+	for _, m := range buffered {
+		data, _ := json.Marshal(m.Message)
+		s.sendSSE(subType, data)
+	}
+	return apiResponse{OK: true}, nil
+}
+
 // doUnsubscribe removes a subscription by lookup key
-func (s *session) doUnsubscribe(subType string, args map[string]any) {
-	lookupKey := subLookupKey(subType, args)
+func (s *session) doUnsubscribe(node gen.Atom, subType string, args map[string]any) {
+	lookupKey := nodeLookupKey(node, subType, args)
+	if subType == "cluster_overview" || subType == "cron" {
+		lookupKey = subType
+	}
 	eventKey, exist := s.subIndex[lookupKey]
 	if exist == false {
 		s.Log().Warning("session %s: unsubscribe %s not found", s.id, lookupKey)
@@ -619,7 +1139,10 @@ func (s *session) doUnsubscribe(subType string, args map[string]any) {
 		s.DemonitorEvent(ev)
 		delete(s.subscriptions, eventKey)
 	}
+	s.discardThrottled(eventKey)
 	delete(s.subIndex, lookupKey)
+	delete(s.logFilters, eventKey)
+	delete(s.heatmaps, eventKey)
 	s.Log().Info("session %s: unsubscribed %s → %s", s.id, lookupKey, eventKey)
 }
 
@@ -645,65 +1168,72 @@ func (s *session) doSwitch(newNode gen.Atom, args map[string]any) (any, error) {
 		return apiResponse{Error: "unexpected response from remote inspect"}, nil
 	}
 
-	// unsubscribe all from current node
-	for key, ev := range s.subscriptions {
-		s.DemonitorEvent(ev)
-		delete(s.subscriptions, key)
+	// unsubscribe all from current node, subscriptions on other nodes stay
+	prefix := string(s.node) + "/"
+	for lookupKey, eventKey := range s.subIndex {
+		if strings.HasPrefix(lookupKey, prefix) == false {
+			continue
+		}
+		if ev, ok := s.subscriptions[eventKey]; ok {
+			s.DemonitorEvent(ev)
+			delete(s.subscriptions, eventKey)
+		}
+		s.discardThrottled(eventKey)
+		delete(s.subIndex, lookupKey)
+		delete(s.logFilters, eventKey)
+		delete(s.heatmaps, eventKey)
 	}
-	s.subIndex = make(map[string]string)
 
 	s.node = newNode
 	s.creation = r.Creation
-	s.sendConnectedEvent()
+	s.cacheCreation(newNode, r.Creation)
+	s.sendConnectedEvent(false)
 	return apiResponse{OK: true}, nil
 }
 
-// tryConnect ensures network connection to the target node.
-// If already connected — no-op. Otherwise tries registrar, then explicit route from args.
-func (s *session) tryConnect(node gen.Atom, args map[string]any) error {
-	// connecting to self
-	if node == s.Node().Name() {
-		return nil
-	}
-
-	// already connected
-	if _, err := s.Node().Network().Node(node); err == nil {
-		return nil
-	}
-
-	// build route from args
-	nr := gen.NetworkRoute{}
-
-	// try registrar first
-	if reg, err := s.Node().Network().Registrar(); err == nil {
-		if routes, err := reg.Resolver().Resolve(node); err == nil && len(routes) > 0 {
-			nr.Route = routes[0]
-		}
-	}
-
-	// override with explicit args
-	if v, ok := args["Cookie"].(string); ok && v != "" {
-		nr.Cookie = v
+// observe returns the creation of the node (needed to parse its PIDs and aliases),
+// connecting to it if this is the first subscription on that node.
+// Args may contain route options: Cookie, Host, Port, TLS.
+func (s *session) observe(node gen.Atom, args map[string]any) (int64, error) {
+	if creation, exist := s.creations[node]; exist {
+		return creation, nil
 	}
-	if v, ok := args["Host"].(string); ok && v != "" {
-		nr.Route.Host = v
+	if err := s.tryConnect(node, args); err != nil {
+		return 0, fmt.Errorf("connect to %s: %s", node, err)
 	}
-	if v, ok := args["Port"].(float64); ok && v > 0 {
-		nr.Route.Port = uint16(v)
+	inspectPID := gen.ProcessID{Name: inspect.Name, Node: node}
+	result, err := s.CallWithTimeout(inspectPID, inspect.RequestInspectNode{}, defaultCallTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("inspect %s: %s", node, err)
 	}
-	if v, ok := args["TLS"].(bool); ok && v {
-		nr.Route.TLS = true
+	r, ok := result.(inspect.ResponseInspectNode)
+	if ok == false {
+		return 0, fmt.Errorf("unexpected response from remote inspect")
 	}
+	s.cacheCreation(node, r.Creation)
+	return r.Creation, nil
+}
 
-	// if we have any route info, use explicit route
-	if nr.Route.Host != "" || nr.Route.Port > 0 || nr.Cookie != "" {
-		_, err := s.Node().Network().GetNodeWithRoute(node, nr)
-		return err
+// cacheCreation keeps the creation of the remote node until it goes down
+// (restarted node gets the new one, its PIDs can't be parsed with the old one)
+func (s *session) cacheCreation(node gen.Atom, creation int64) {
+	if node == s.Node().Name() {
+		return
 	}
+	if _, exist := s.creations[node]; exist == false {
+		if err := s.MonitorNode(node); err != nil {
+			// not cached, the next subscription inspects the node again
+			s.Log().Warning("session %s: unable to monitor node %s: %s", s.id, node, err)
+			return
+		}
+	}
+	s.creations[node] = creation
+}
 
-	// fallback: auto-discovery
-	_, err := s.Node().Network().GetNode(node)
-	return err
+// tryConnect ensures network connection to the target node.
+// If already connected — no-op. Otherwise tries registrar, then explicit route from args.
+func (s *session) tryConnect(node gen.Atom, args map[string]any) error {
+	return connectNode(s.Node(), node, args)
 }
 
 type nodeDesc struct {
@@ -742,7 +1272,8 @@ func (s *session) collectNodes() []nodeDesc {
 // sendConnectedEvent sends session info to browser via SSE.
 // Includes peers (connected nodes) and cluster nodes (from registrar).
 // Also subscribes to registrar event for cluster changes.
-func (s *session) sendConnectedEvent() {
+// Resumed is set if the session survived an SSE reconnect with its subscriptions.
+func (s *session) sendConnectedEvent(resumed bool) {
 	nodes := s.collectNodes()
 
 	// subscribe to registrar event for cluster changes (skip if already subscribed)
@@ -759,24 +1290,51 @@ func (s *session) sendConnectedEvent() {
 	}
 
 	intro := struct {
-		SessionID string      `json:"SessionID"`
-		Node      nodeDesc    `json:"Node"`
-		Nodes     []nodeDesc  `json:"Nodes"`
-		Version   gen.Version `json:"Version"`
+		SessionID    string       `json:"SessionID"`
+		Node         nodeDesc     `json:"Node"`
+		Nodes        []nodeDesc   `json:"Nodes"`
+		Version      gen.Version  `json:"Version"`
+		Capabilities capabilities `json:"Capabilities"`
+		Resumed      bool         `json:"Resumed,omitempty"`
 	}{
-		SessionID: s.id,
-		Node:      nodeDesc{Name: s.node, CRC32: s.node.CRC32(), Connected: true},
-		Nodes:     nodes,
-		Version:   Version,
+		SessionID:    s.id,
+		Node:         nodeDesc{Name: s.node, CRC32: s.node.CRC32(), Connected: true},
+		Nodes:        nodes,
+		Version:      Version,
+		Capabilities: s.capabilities,
+		Resumed:      resumed,
 	}
 
 	data, _ := json.Marshal(intro)
-	s.eventCounter++
-	s.SendAlias(s.sseAlias, sse.Message{
-		Event: "connected",
-		Data:  data,
-		MsgID: fmt.Sprintf("%d", s.eventCounter),
-	})
+	s.sendSSE("connected", data)
+}
+
+// alertsLookupKey is the subIndex key of the alerts event subscription
+const alertsLookupKey = "alerts_event"
+
+// subscribeAlerts monitors the alerts event and sends the recent alerts to the browser.
+// Fails silently if alerting is disabled (no rules, the event is not registered).
+func (s *session) subscribeAlerts() {
+	event := gen.Event{Name: alertsEvent, Node: s.Node().Name()}
+	buffered, err := s.MonitorEvent(event)
+	if err != nil {
+		return
+	}
+	s.subscriptions[event.String()] = event
+	s.subIndex[alertsLookupKey] = event.String()
+	for _, m := range buffered {
+		s.sendAlert(m.Message)
+	}
+}
+
+// sendAlert sends the alert to the browser right away, alerts are never coalesced
+func (s *session) sendAlert(alert any) {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		s.Log().Error("session %s: marshal alert: %s", s.id, err)
+		return
+	}
+	s.sendSSE("alert", data)
 }
 
 // sendClusterUpdate re-reads cluster nodes and sends update to browser
@@ -786,16 +1344,11 @@ func (s *session) sendClusterUpdate() {
 	}{Nodes: s.collectNodes()}
 
 	data, _ := json.Marshal(payload)
-	s.eventCounter++
-	s.SendAlias(s.sseAlias, sse.Message{
-		Event: "cluster_update",
-		Data:  data,
-		MsgID: fmt.Sprintf("%d", s.eventCounter),
-	})
+	s.sendSSE("cluster_update", data)
 }
 
 // sendInitialData sends extra info from the inspect response as a separate SSE event
-func (s *session) sendInitialData(subType string, result any) {
+func (s *session) sendInitialData(node gen.Atom, subType string, result any) {
 	switch subType {
 	case "node_info":
 		r, ok := result.(inspect.ResponseInspectNode)
@@ -821,17 +1374,63 @@ func (s *session) sendInitialData(subType string, result any) {
 			Creation: r.Creation,
 		}
 		data, _ := json.Marshal(meta)
-		s.eventCounter++
-		s.SendAlias(s.sseAlias, sse.Message{
-			Event: "node_meta",
-			Data:  data,
-			MsgID: fmt.Sprintf("%d", s.eventCounter),
-		})
+		s.sendSSE("node_meta", tagNode(data, node))
+
+		// history is kept for the local node only
+		if node != s.Node().Name() {
+			return
+		}
+		h, err := s.Call(historyName, requestHistory{})
+		if err != nil {
+			// history is disabled
+			return
+		}
+		data, _ = json.Marshal(h)
+		s.sendSSE("node_history", tagNode(data, node))
+	}
+}
+
+// setLogFilter keeps the filter of the log subscription, the empty one passes everything
+func (s *session) setLogFilter(eventKey string, filter *logFilter) {
+	if filter.empty() {
+		delete(s.logFilters, eventKey)
+		return
+	}
+	s.logFilters[eventKey] = filter
+}
+
+// sendLogBacklog sends the recent log messages of the node running Observer matching
+// the filter as the "log" event with Backlog set. Args may limit the number of them.
+func (s *session) sendLogBacklog(node gen.Atom, filter *logFilter, args map[string]any) {
+	if node != s.Node().Name() {
+		// the backlog is kept for the local node only
+		return
+	}
+	limit, _ := args["limit"].(float64)
+	result, err := s.Call(logsName, requestLogBacklog{Filter: filter, Limit: int(limit)})
+	if err != nil {
+		// the backlog is disabled
+		return
+	}
+	entries, _ := result.([]logEntry)
+	if len(entries) == 0 {
+		return
+	}
+	backlog := struct {
+		Node    gen.Atom   `json:"Node"`
+		Entries []logEntry `json:"Entries"`
+		Backlog bool       `json:"Backlog"`
+	}{
+		Node:    node,
+		Entries: entries,
+		Backlog: true,
 	}
+	data, _ := json.Marshal(backlog)
+	s.sendSSE("log", tagNode(data, node))
 }
 
-// buildInspectRequest creates RequestInspect* for the subscription type
-func (s *session) buildInspectRequest(subType string, args map[string]any) (any, error) {
+// buildInspectRequest creates RequestInspect* for the subscription type on the given node
+func (s *session) buildInspectRequest(node gen.Atom, creation int64, subType string, args map[string]any) (any, error) {
 	switch subType {
 	case "node_info":
 		return inspect.RequestInspectNode{}, nil
@@ -876,7 +1475,7 @@ func (s *session) buildInspectRequest(subType string, args map[string]any) (any,
 		if pid == "" {
 			return nil, fmt.Errorf("pid is required")
 		}
-		p, err := str2pid(s.node, s.creation, pid)
+		p, err := str2pid(node, creation, pid)
 		if err != nil {
 			return nil, fmt.Errorf("invalid pid %q: %s", pid, err)
 		}
@@ -887,7 +1486,7 @@ func (s *session) buildInspectRequest(subType string, args map[string]any) (any,
 		if pid == "" {
 			return nil, fmt.Errorf("pid is required")
 		}
-		p, err := str2pid(s.node, s.creation, pid)
+		p, err := str2pid(node, creation, pid)
 		if err != nil {
 			return nil, fmt.Errorf("invalid pid %q: %s", pid, err)
 		}
@@ -901,7 +1500,7 @@ func (s *session) buildInspectRequest(subType string, args map[string]any) (any,
 		if alias == "" {
 			return nil, fmt.Errorf("alias is required")
 		}
-		a, err := str2alias(s.node, s.creation, alias)
+		a, err := str2alias(node, creation, alias)
 		if err != nil {
 			return nil, fmt.Errorf("invalid alias %q: %s", alias, err)
 		}
@@ -915,18 +1514,18 @@ func (s *session) buildInspectRequest(subType string, args map[string]any) (any,
 		if alias == "" {
 			return nil, fmt.Errorf("alias is required")
 		}
-		a, err := str2alias(s.node, s.creation, alias)
+		a, err := str2alias(node, creation, alias)
 		if err != nil {
 			return nil, fmt.Errorf("invalid alias %q: %s", alias, err)
 		}
 		return inspect.RequestInspectMetaState{Meta: a}, nil
 
 	case "connection_info":
-		node, _ := args["node"].(string)
-		if node == "" {
+		remote, _ := args["node"].(string)
+		if remote == "" {
 			return nil, fmt.Errorf("node is required")
 		}
-		return inspect.RequestInspectConnection{RemoteNode: gen.Atom(node)}, nil
+		return inspect.RequestInspectConnection{RemoteNode: gen.Atom(remote)}, nil
 
 	case "network_info":
 		return inspect.RequestInspectNetwork{}, nil
@@ -978,6 +1577,9 @@ func (s *session) buildInspectRequest(subType string, args map[string]any) (any,
 		}
 		return req, nil
 
+	case "process_heatmap":
+		return heatmapRequest(args), nil
+
 	case "application_list":
 		return inspect.RequestInspectApplicationList{}, nil
 
@@ -1105,6 +1707,10 @@ func inspectEventToSSEType(name gen.Atom) string {
 		return "log"
 	case strings.HasPrefix(n, "inspect_heap"):
 		return "heap"
+	case name == clusterEvent:
+		return "cluster_overview"
+	case name == cronEvent:
+		return "cron"
 	}
 	return n
 }
@@ -1117,6 +1723,12 @@ func extractIDFromLookupKey(key string) string {
 	return ""
 }
 
+// nodeLookupKey prefixes the lookup key with the node, so the same subscription
+// on different nodes gets different keys. Example: "node@host/process_info:pid=<9F35C982.0.1006>"
+func nodeLookupKey(node gen.Atom, subType string, args map[string]any) string {
+	return string(node) + "/" + subLookupKey(subType, args)
+}
+
 // subLookupKey builds a stable key from subscription type + args for O(1) lookup.
 // Examples: "node_info", "process_info:pid=<9F35C982.0.1006>", "connection_info:node=remote@host"
 func subLookupKey(subType string, args map[string]any) string {
@@ -1167,6 +1779,11 @@ func subLookupKey(subType string, args map[string]any) string {
 		}
 		return fmt.Sprintf("%s:start=%d:limit=%d:name=%s:beh=%s:app=%s:state=%s:mbox=%d",
 			subType, int(start), int(limit), nameP, behaviorP, appP, stateP, int(mailboxP))
+	case "process_heatmap":
+		nameP, _ := args["namePattern"].(string)
+		behaviorP, _ := args["behavior"].(string)
+		appP, _ := args["application"].(string)
+		return fmt.Sprintf("%s:name=%s:beh=%s:app=%s", subType, nameP, behaviorP, appP)
 	case "connection_list":
 		clLimit, _ := args["limit"].(float64)
 		clName, _ := args["namePattern"].(string)
@@ -0,0 +1,131 @@
+package observer
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// defaultCoalesceWindow is used if MaxPendingEvents is set without
+// EventCoalesceWindow: the backlog is checked on flush only.
+const defaultCoalesceWindow = 200 * time.Millisecond
+
+// maxQueuedEvents limits the events that are never coalesced (log entries)
+// kept between flushes. Beyond it they are dropped.
+const maxQueuedEvents = 1000
+
+type throttledEvent struct {
+	event string
+	data  []byte
+}
+
+// messageSessionFlush sent by session to itself when the coalescing window ends
+type messageSessionFlush struct{}
+
+// throttleSSE queues the inspect event until the end of the coalescing window.
+// Events of the same subscription (key) replace each other, so only the latest
+// one is sent. Log entries are never coalesced, each of them matters.
+func (s *session) throttleSSE(key string, event string, data []byte) {
+	if s.coalesceWindow <= 0 {
+		s.sendSSE(event, data)
+		return
+	}
+
+	if event == "log" {
+		if len(s.queued) >= maxQueuedEvents {
+			s.dropped++
+		} else {
+			s.queued = append(s.queued, throttledEvent{event: event, data: data})
+		}
+	} else {
+		if _, exist := s.coalesced[key]; exist == false {
+			s.coalesceOrder = append(s.coalesceOrder, key)
+		}
+		s.coalesced[key] = throttledEvent{event: event, data: data}
+	}
+
+	if s.flushScheduled == false {
+		s.flushScheduled = true
+		s.SendAfter(s.PID(), messageSessionFlush{}, s.coalesceWindow)
+	}
+}
+
+// discardThrottled removes the queued event of the subscription. Must be called
+// on every path that drops a subscription (its queued data is outdated).
+func (s *session) discardThrottled(key string) {
+	if _, exist := s.coalesced[key]; exist == false {
+		return
+	}
+	delete(s.coalesced, key)
+	for i, k := range s.coalesceOrder {
+		if k == key {
+			s.coalesceOrder = append(s.coalesceOrder[:i], s.coalesceOrder[i+1:]...)
+			break
+		}
+	}
+	if s.degraded && s.flushScheduled == false {
+		// nothing may be queued anymore, the flush re-checks the backlog
+		s.flushScheduled = true
+		s.SendAfter(s.PID(), messageSessionFlush{}, s.coalesceWindow)
+	}
+}
+
+// flushSSE sends the queued events. If the SSE connection has more pending
+// messages than allowed (slow client), the frame is skipped: coalesced events
+// wait for the next window (newer data replaces them), log entries are dropped.
+func (s *session) flushSSE() {
+	s.flushScheduled = false
+	if len(s.coalesceOrder) == 0 && len(s.queued) == 0 && s.degraded == false {
+		return
+	}
+
+	if s.maxPending > 0 && s.detachedAt.IsZero() {
+		if info, err := s.Node().MetaInfo(s.sseAlias); err == nil {
+			pending := info.MailboxQueues.Main
+			if pending > s.maxPending {
+				s.dropped += int64(len(s.queued))
+				s.queued = s.queued[:0]
+				s.setDegraded(true, pending)
+				s.flushScheduled = true
+				s.SendAfter(s.PID(), messageSessionFlush{}, s.coalesceWindow)
+				return
+			}
+			s.setDegraded(false, pending)
+		}
+	}
+
+	for _, key := range s.coalesceOrder {
+		ev := s.coalesced[key]
+		s.sendSSE(ev.event, ev.data)
+		delete(s.coalesced, key)
+	}
+	s.coalesceOrder = s.coalesceOrder[:0]
+
+	for _, ev := range s.queued {
+		s.sendSSE(ev.event, ev.data)
+	}
+	s.queued = s.queued[:0]
+}
+
+// setDegraded notifies the frontend when the session starts or stops skipping frames
+func (s *session) setDegraded(degraded bool, pending int64) {
+	if s.degraded == degraded {
+		return
+	}
+	s.degraded = degraded
+	if degraded {
+		s.Log().Warning("session %s: client is behind (%d pending), skipping frames", s.id, pending)
+	} else {
+		s.Log().Info("session %s: client caught up, %d events dropped", s.id, s.dropped)
+	}
+
+	data, _ := json.Marshal(struct {
+		Degraded bool  `json:"Degraded"`
+		Pending  int64 `json:"Pending"`
+		Dropped  int64 `json:"Dropped"`
+	}{
+		Degraded: degraded,
+		Pending:  pending,
+		Dropped:  s.dropped,
+	})
+	s.sendSSE("degraded", data)
+}
@@ -0,0 +1,92 @@
+package observer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"ergo.services/ergo/gen"
+)
+
+// createMTLSServer creates a meta process serving HTTPS with client certificate
+// verification. meta.WebServer has no option to request client certificates,
+// so the listener is created here with the TLS config built from CertManager.
+func createMTLSServer(host string, port uint16, cm gen.CertManager, clientCAs *x509.CertPool, handler http.Handler) (gen.MetaBehavior, error) {
+	config := &tls.Config{
+		GetCertificate: cm.GetCertificateFunc(),
+		ClientCAs:      clientCAs,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	listener, err := tls.Listen("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mtlsServer{
+		listener: listener,
+		server:   &http.Server{Handler: handler},
+	}, nil
+}
+
+type mtlsServer struct {
+	gen.MetaProcess
+	listener net.Listener
+	server   *http.Server
+}
+
+func (s *mtlsServer) Init(process gen.MetaProcess) error {
+	s.MetaProcess = process
+	return nil
+}
+
+func (s *mtlsServer) Start() error {
+	err := s.server.Serve(s.listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *mtlsServer) HandleMessage(from gen.PID, message any) error {
+	return nil
+}
+
+func (s *mtlsServer) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	return gen.ErrUnsupported, nil
+}
+
+func (s *mtlsServer) Terminate(reason error) {
+	s.server.Close()
+	s.listener.Close()
+}
+
+func (s *mtlsServer) HandleInspect(from gen.PID, item ...string) map[string]string {
+	return map[string]string{
+		"listener":    s.listener.Addr().String(),
+		"client auth": "require and verify",
+	}
+}
+
+// certIdentity uses the subject of the verified client certificate as the identity
+// of the request, unless it was already authenticated by AuthOptions. It must be
+// wrapped by authHandler, so the certificate never bypasses the configured Users/Tokens.
+func certIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Identity(r) == "" && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			cert := r.TLS.VerifiedChains[0][0]
+			identity := cert.Subject.CommonName
+			if identity == "" {
+				identity = fmt.Sprintf("cert:%s", cert.SerialNumber)
+			}
+			r = WithIdentity(r, identity)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,323 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ergo.services/ergo/gen"
+	"ergo.services/ergo/net/edf"
+)
+
+// maxCallTimeout keeps the "call" action within the timeout of the POST request
+const maxCallTimeout = defaultCallTimeout - 1 // seconds
+
+// typeField describes a field of the registered type for the message composer
+type typeField struct {
+	Name string `json:"Name"`
+	Type string `json:"Type"`
+	Tag  string `json:"Tag,omitempty"`
+}
+
+type typeInfo struct {
+	Name   string      `json:"Name"`
+	Kind   string      `json:"Kind"`
+	Fields []typeField `json:"Fields"`
+}
+
+// messageTypes returns the names of EDF-registered types (sorted), optionally filtered
+func messageTypes(filter string) []string {
+	filter = strings.ToLower(filter)
+	names := []string{}
+	for name := range edf.RegisteredTypes() {
+		if filter != "" && strings.Contains(strings.ToLower(name), filter) == false {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// messageTypeInfo describes the registered type, so the frontend can build a form for it
+func messageTypeInfo(name string) (typeInfo, error) {
+	t, ok := edf.LookupType(name)
+	if ok == false {
+		return typeInfo{}, fmt.Errorf("type %s is not registered", name)
+	}
+	info := typeInfo{
+		Name:   t.PkgPath() + "/" + t.Name(),
+		Kind:   t.Kind().String(),
+		Fields: []typeField{},
+	}
+	if t.Kind() != reflect.Struct {
+		return info, nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		info.Fields = append(info.Fields, typeField{
+			Name: f.Name,
+			Type: f.Type.String(),
+			Tag:  f.Tag.Get("json"),
+		})
+	}
+	return info, nil
+}
+
+// typedMessage creates the value of the registered type filled from the JSON-decoded
+// value (an object with the field values, or the value itself for non-struct types).
+// Without the type name the value is used as is.
+func typedMessage(name string, value any) (any, error) {
+	if name == "" {
+		return value, nil
+	}
+	t, ok := edf.LookupType(name)
+	if ok == false {
+		return nil, fmt.Errorf("type %s is not registered", name)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		// the composer sends the values as typed in ("42" for an int field),
+		// coerce them field by field
+		v = reflect.New(t)
+		if m, ok := value.(map[string]any); ok && t.Kind() == reflect.Struct {
+			if ferr := fillStructFromMap(v.Elem(), m); ferr != nil {
+				return nil, fmt.Errorf("unable to build %s: %s", name, ferr)
+			}
+		} else if ferr := setFieldValue(v.Elem(), value); ferr != nil {
+			return nil, fmt.Errorf("unable to build %s: %s", name, ferr)
+		}
+	}
+	return v.Elem().Interface(), nil
+}
+
+// fillStructFromMap and setFieldValue are a copy of the ones in mcp/types.go,
+// which is the source of truth: the modules can't import each other.
+// Change them there first and keep this copy identical.
+func fillStructFromMap(v reflect.Value, m map[string]any) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" && parts[0] != "-" {
+				name = parts[0]
+			}
+		}
+
+		val, ok := m[name]
+		if ok == false {
+			val, ok = m[field.Name]
+		}
+		if ok == false {
+			continue
+		}
+
+		if err := setFieldValue(v.Field(i), val); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func setFieldValue(field reflect.Value, val any) error {
+	if val == nil {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		s, ok := val.(string)
+		if ok == false {
+			s = fmt.Sprintf("%v", val)
+		}
+		field.SetString(s)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch v := val.(type) {
+		case float64:
+			field.SetInt(int64(v))
+		case string:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return err
+			}
+			field.SetInt(n)
+		default:
+			return fmt.Errorf("cannot convert %T to int", val)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v := val.(type) {
+		case float64:
+			field.SetUint(uint64(v))
+		case string:
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return err
+			}
+			field.SetUint(n)
+		default:
+			return fmt.Errorf("cannot convert %T to uint", val)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		switch v := val.(type) {
+		case float64:
+			field.SetFloat(v)
+		case string:
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return err
+			}
+			field.SetFloat(n)
+		default:
+			return fmt.Errorf("cannot convert %T to float", val)
+		}
+
+	case reflect.Bool:
+		switch v := val.(type) {
+		case bool:
+			field.SetBool(v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+		default:
+			return fmt.Errorf("cannot convert %T to bool", val)
+		}
+
+	default:
+		// For complex types, try JSON round-trip
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Errorf("cannot marshal value: %w", err)
+		}
+		ptr := reflect.New(field.Type())
+		if err := json.Unmarshal(b, ptr.Interface()); err != nil {
+			return fmt.Errorf("cannot unmarshal into %s: %w", field.Type(), err)
+		}
+		field.Set(ptr.Elem())
+	}
+
+	return nil
+}
+
+// target resolves the receiver of the typed send/call on the observed node:
+// args "pid", "alias" or "name" (registered process name).
+func (s *session) target(args map[string]any) (any, error) {
+	if v, _ := args["pid"].(string); v != "" {
+		return str2pid(s.node, s.creation, v)
+	}
+	if v, _ := args["alias"].(string); v != "" {
+		return str2alias(s.node, s.creation, v)
+	}
+	if v, _ := args["name"].(string); v != "" {
+		return gen.ProcessID{Name: gen.Atom(v), Node: s.node}, nil
+	}
+	return nil, fmt.Errorf("pid, alias or name is required")
+}
+
+// handleTypedAction serves the actions of the message composer but "call"
+// (see prepareTypedCall). Typed messages are sent by the Observer node, so the type
+// must be registered on both nodes if the observed node is a remote one.
+func (s *session) handleTypedAction(action string, args map[string]any) apiResponse {
+	switch action {
+	case "message_types":
+		filter, _ := args["filter"].(string)
+		return apiResponse{OK: true, Data: messageTypes(filter)}
+
+	case "message_type_info":
+		name, _ := args["type"].(string)
+		info, err := messageTypeInfo(name)
+		if err != nil {
+			return apiResponse{Error: err.Error()}
+		}
+		return apiResponse{OK: true, Data: info}
+	}
+
+	to, err := s.target(args)
+	if err != nil {
+		return apiResponse{Error: err.Error()}
+	}
+	name, _ := args["type"].(string)
+	message, err := typedMessage(name, args["message"])
+	if err != nil {
+		return apiResponse{Error: err.Error()}
+	}
+	important, _ := args["important"].(bool)
+	if important {
+		err = s.SendImportant(to, message)
+	} else {
+		err = s.Send(to, message)
+	}
+	if err != nil {
+		return apiResponse{Error: fmt.Sprintf("send %s: %s", name, err)}
+	}
+	return apiResponse{OK: true}
+}
+
+// typedCall is returned by the session for the "call" action of the composer.
+// The call is made by the post worker, so the session keeps delivering SSE
+// events while waiting for the response.
+type typedCall struct {
+	To      any
+	Message any
+	Type    string
+	Timeout int // seconds
+}
+
+// prepareTypedCall returns typedCall or apiResponse with the error
+func (s *session) prepareTypedCall(args map[string]any) any {
+	to, err := s.target(args)
+	if err != nil {
+		return apiResponse{Error: err.Error()}
+	}
+	name, _ := args["type"].(string)
+	message, err := typedMessage(name, args["message"])
+	if err != nil {
+		return apiResponse{Error: err.Error()}
+	}
+	timeout := maxCallTimeout
+	if v, ok := args["timeout"].(float64); ok && v >= 1 && int(v) < timeout {
+		timeout = int(v)
+	}
+	return typedCall{To: to, Message: message, Type: name, Timeout: timeout}
+}
+
+func (w *postWorker) doTypedCall(c typedCall) apiResponse {
+	response, err := w.CallWithTimeout(c.To, c.Message, c.Timeout)
+	if err != nil {
+		return apiResponse{Error: fmt.Sprintf("call %s: %s", c.Type, err)}
+	}
+
+	result := struct {
+		Type     string `json:"Type"`
+		Response any    `json:"Response"`
+	}{
+		Type:     fmt.Sprintf("%T", response),
+		Response: response,
+	}
+	if _, err := json.Marshal(response); err != nil {
+		// not JSON-serializable
+		result.Response = fmt.Sprintf("%#v", response)
+	}
+	return apiResponse{OK: true, Data: result}
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"ergo.services/ergo/act"
@@ -26,16 +28,21 @@ func factory_web() gen.ProcessBehavior {
 // Does not handle messages — SSE connect/disconnect goes to mgr via ProcessPool.
 type web struct {
 	act.Actor
+	options Options
 }
 
 func (w *web) Init(args ...any) error {
+	w.options = args[0].(Options)
 	w.Log().SetLogger("default")
 
 	v, _ := w.Env("port")
 	port, _ := v.(uint16)
-	if port < 1 {
+	if port < 1 && w.options.Mux == nil {
 		return errors.New("port is not set")
 	}
+	if w.options.Mux != nil && normalizePrefix(w.options.PathPrefix) == "" {
+		return errors.New("PathPrefix is required to attach to the external mux")
+	}
 
 	host := "localhost"
 	if v, exist := w.Env("host"); exist {
@@ -54,7 +61,13 @@ func (w *web) Init(args ...any) error {
 	if _, err := w.SpawnMeta(sseHandler, gen.MetaOptions{}); err != nil {
 		return err
 	}
-	mux.Handle("/sse", sseHandler)
+	prefix := normalizePrefix(w.options.PathPrefix)
+	if w.options.RecordDir != "" {
+		mux.Handle("/sse", replayHandler(w.options.RecordDir, prefix, sseHandler))
+		mux.Handle("/replay/", replayStartHandler(w.options.RecordDir, prefix))
+	} else {
+		mux.Handle("/sse", sseHandler)
+	}
 
 	// POST /api/* → WebHandler → post pool
 	postHandler := meta.CreateWebHandler(meta.WebHandlerOptions{
@@ -66,30 +79,159 @@ func (w *web) Init(args ...any) error {
 	}
 	mux.Handle("/api/", postHandler)
 
+	// GET /api/v1/* → WebHandler → rest (headless JSON API, no SSE session)
+	restHandler := meta.CreateWebHandler(meta.WebHandlerOptions{
+		Worker:         restName,
+		RequestTimeout: 15 * time.Second,
+	})
+	if _, err := w.SpawnMeta(restHandler, gen.MetaOptions{}); err != nil {
+		return err
+	}
+	mux.Handle("/api/v1/", restHandler)
+	if w.options.RecordDir != "" {
+		mux.Handle("/api/v1/recordings", recordingsHandler(w.options.RecordDir))
+		mux.Handle("/api/v1/recordings/", recordingsHandler(w.options.RecordDir))
+	}
+
 	// static frontend assets with pre-compressed gzip support
 	fsroot, _ := fs.Sub(assets, "web")
-	mux.HandleFunc("/", gzipFileServer(fsroot))
+	var rewritten map[string][]byte
+	if prefix != "" {
+		files, missing, err := rewriteAssets(fsroot, prefix)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			w.Log().Warning("frontend adapted to prefix %s, index.html patterns not found: %q",
+				prefix, missing)
+		}
+		rewritten = files
+	}
+	mux.HandleFunc("/", gzipFileServer(fsroot, rewritten))
+
+	var inner http.Handler = mux
+	if w.options.Mux == nil && w.options.ClientCAs != nil {
+		// runs after the Users/Tokens check, the certificate doesn't replace it
+		inner = certIdentity(mux)
+	}
+	handler := authHandler(w.options.Auth, inner)
+	if prefix != "" {
+		handler = http.StripPrefix(prefix, handler)
+	}
+
+	// attach to the external mux, no own web server
+	if w.options.Mux != nil {
+		mount(w.options.Mux, prefix+"/").Store(handler)
+		w.Log().Info("Observer attached to external mux at %s/", prefix)
+		return nil
+	}
+
+	if prefix != "" {
+		root := http.NewServeMux()
+		root.Handle(prefix+"/", handler)
+		handler = root
+	}
 
 	// web server
-	webserver, err := meta.CreateWebServer(meta.WebServerOptions{
-		Port:    port,
-		Host:    host,
-		Handler: mux,
-	})
+	var webserver gen.MetaBehavior
+	var err error
+	scheme := "http"
+	switch {
+	case w.options.ClientCAs != nil:
+		if w.options.CertManager == nil {
+			return errors.New("ClientCAs requires CertManager")
+		}
+		scheme = "https (mTLS)"
+		webserver, err = createMTLSServer(host, port, w.options.CertManager, w.options.ClientCAs, handler)
+	default:
+		if w.options.CertManager != nil {
+			scheme = "https"
+		}
+		webserver, err = meta.CreateWebServer(meta.WebServerOptions{
+			Port:        port,
+			Host:        host,
+			Handler:     handler,
+			CertManager: w.options.CertManager,
+		})
+	}
 	if err != nil {
 		return err
 	}
 	if _, err := w.SpawnMeta(webserver, gen.MetaOptions{}); err != nil {
+		webserver.Terminate(err)
 		return err
 	}
 
-	w.Log().Info("Observer listening on %s:%d", host, port)
+	if w.options.Auth.enabled() == false && w.options.ClientCAs == nil {
+		w.Log().Warning("Observer authentication is disabled, anyone with access to %s:%d can perform actions", host, port)
+	}
+	w.Log().Info("Observer listening on %s:%d%s/ (%s)", host, port, prefix, scheme)
 	return nil
 }
 
+func (w *web) Terminate(reason error) {
+	if w.options.Mux == nil {
+		return
+	}
+	// the pattern stays registered on the external mux (ServeMux can't remove it)
+	mount(w.options.Mux, normalizePrefix(w.options.PathPrefix)+"/").Store(http.HandlerFunc(notRunning))
+}
+
+// mounts keeps the handlers registered on the external muxes. ServeMux panics
+// on the duplicate registration, so the pattern is registered once and
+// the handler behind it is replaced on every (re)start of the web process.
+var mounts sync.Map // mountKey → *mountHandler
+
+type mountKey struct {
+	mux     *http.ServeMux
+	pattern string
+}
+
+type mountHandler struct {
+	handler atomic.Value // http.Handler
+}
+
+func (m *mountHandler) Store(handler http.Handler) {
+	m.handler.Store(&handler)
+}
+
+func (m *mountHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h, _ := m.handler.Load().(*http.Handler)
+	if h == nil {
+		notRunning(w, r)
+		return
+	}
+	(*h).ServeHTTP(w, r)
+}
+
+func mount(mux *http.ServeMux, pattern string) *mountHandler {
+	key := mountKey{mux: mux, pattern: pattern}
+	if v, exist := mounts.Load(key); exist {
+		return v.(*mountHandler)
+	}
+	v, loaded := mounts.LoadOrStore(key, &mountHandler{})
+	m := v.(*mountHandler)
+	if loaded == false {
+		mux.Handle(pattern, m)
+	}
+	return m
+}
+
+func notRunning(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "Observer is not running", http.StatusServiceUnavailable)
+}
+
 // gzipFileServer serves pre-compressed .gz files when client supports gzip.
 // Falls back to index.html for SPA routing.
-func gzipFileServer(fsys fs.FS) http.HandlerFunc {
+// Files in rewritten (adapted to the path prefix) take precedence over fsys.
+func gzipFileServer(fsys fs.FS, rewritten map[string][]byte) http.HandlerFunc {
+	readFile := func(name string) ([]byte, error) {
+		if data, ok := rewritten[name]; ok {
+			return data, nil
+		}
+		return fs.ReadFile(fsys, name)
+	}
+
 	contentTypes := map[string]string{
 		".js":   "application/javascript",
 		".css":  "text/css",
@@ -106,7 +248,7 @@ func gzipFileServer(fsys fs.FS) http.HandlerFunc {
 
 		// try .gz file (pre-compressed at build time, originals removed)
 		gzPath := path + ".gz"
-		if data, err := fs.ReadFile(fsys, gzPath); err == nil {
+		if data, err := readFile(gzPath); err == nil {
 			ext := filepath.Ext(path)
 			if ct, ok := contentTypes[ext]; ok {
 				w.Header().Set("Content-Type", ct)
@@ -128,7 +270,7 @@ func gzipFileServer(fsys fs.FS) http.HandlerFunc {
 		}
 
 		// SPA fallback: serve index.html.gz
-		if data, err := fs.ReadFile(fsys, "index.html.gz"); err == nil {
+		if data, err := readFile("index.html.gz"); err == nil {
 			w.Header().Set("Content-Type", "text/html")
 			w.Header().Set("Content-Encoding", "gzip")
 			w.Header().Set("Vary", "Accept-Encoding")
@@ -5,6 +5,7 @@ import (
 
 	"ergo.services/actor/metrics"
 	"ergo.services/ergo/gen"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // CreateApp returns an ApplicationBehavior that bundles health checks and
@@ -36,10 +37,18 @@ func (a *radarApp) Load(node gen.Node, args ...any) (gen.ApplicationSpec, error)
 	mux := http.NewServeMux()
 	shared := metrics.NewShared()
 
+	// actor/metrics serves its metrics on the internal mux, they are exposed
+	// merged with the radar registry (summaries and native histograms)
+	metricsMux := http.NewServeMux()
+	registry := prometheus.NewRegistry()
+	mux.Handle(a.options.MetricsPath, metricsHandler(metricsMux, a.options.MetricsPath, registry))
+
 	env := map[gen.Env]any{
-		"mux":     mux,
-		"shared":  shared,
-		"options": a.options,
+		"mux":         mux,
+		"metrics_mux": metricsMux,
+		"shared":      shared,
+		"registry":    registry,
+		"options":     a.options,
 	}
 
 	return gen.ApplicationSpec{
@@ -0,0 +1,96 @@
+package radar
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AuthOptions configures authentication of the Radar HTTP endpoint.
+// Both methods are accepted if configured: a request passes if it matches any of them.
+// Empty = no auth.
+type AuthOptions struct {
+	// Users for HTTP Basic authentication: username -> password.
+	Users map[string]string
+
+	// Tokens for Bearer authentication (Prometheus "authorization" scrape option).
+	Tokens []string
+
+	// Health requires authentication on the health endpoints as well.
+	// By default they stay open, so the Kubernetes probes keep working.
+	Health bool
+
+	// Realm for the Basic authentication challenge. Default: "radar"
+	Realm string
+}
+
+func (a AuthOptions) enabled() bool {
+	return len(a.Users) > 0 || len(a.Tokens) > 0
+}
+
+// authHandler protects every path of the handler but the health ones (unless
+// Options.Auth.Health is set) with the client certificate (if Options.ClientCAs
+// is given) and the Basic/Bearer authentication.
+func authHandler(options Options, next http.Handler) http.Handler {
+	auth := options.Auth
+	if auth.enabled() == false && options.ClientCAs == nil {
+		return next
+	}
+
+	realm := auth.Realm
+	if realm == "" {
+		realm = "radar"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.Health == false && isHealthPath(options.HealthPath, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if options.ClientCAs != nil && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			http.Error(w, "Client certificate required", http.StatusUnauthorized)
+			return
+		}
+
+		if auth.enabled() && authenticate(auth, r) == false {
+			if len(auth.Users) > 0 {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+			} else {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isHealthPath(prefix string, path string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func authenticate(auth AuthOptions, r *http.Request) bool {
+	if user, password, ok := r.BasicAuth(); ok {
+		expected, exist := auth.Users[user]
+		return exist && secureEqual(expected, password)
+	}
+
+	header := r.Header.Get("Authorization")
+	if strings.HasPrefix(header, "Bearer ") == false {
+		return false
+	}
+	token := strings.TrimPrefix(header, "Bearer ")
+	for _, t := range auth.Tokens {
+		if secureEqual(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
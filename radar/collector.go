@@ -0,0 +1,224 @@
+package radar
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"ergo.services/ergo/act"
+	"ergo.services/ergo/gen"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collector keeps the metrics actor/metrics has no support for: summaries and
+// native histograms. They are registered in the radar registry, which is
+// exposed along with the metrics of actor/metrics (see metricsHandler).
+type collector struct {
+	act.Actor
+
+	registrar prometheus.Registerer // radar registry, adds the "node" label
+
+	metrics map[string]*collectorMetric
+	owners  map[gen.PID][]string // registering process → its metrics
+}
+
+type collectorMetric struct {
+	owner     gen.PID
+	collector prometheus.Collector
+	observe   func(labels []string) (prometheus.Observer, error)
+}
+
+type requestRegisterSummary struct {
+	Name    string
+	Help    string
+	Labels  []string
+	Options SummaryOptions
+}
+
+type requestRegisterHistogram struct {
+	Name    string
+	Help    string
+	Labels  []string
+	Buckets []float64
+	Options HistogramOptions
+}
+
+type requestUnregister struct {
+	Name string
+}
+
+type messageObserve struct {
+	Name   string
+	Value  float64
+	Labels []string
+}
+
+const (
+	defaultNativeMaxBucketNumber  = 160
+	defaultNativeMinResetDuration = time.Hour
+)
+
+// collected holds the names of the metrics registered with the collector
+// on every node, so the helpers can route the updates to it.
+var collected sync.Map // collectedKey → struct{}
+
+type collectedKey struct {
+	node gen.Atom
+	name string
+}
+
+func isCollected(process gen.Process, name string) bool {
+	_, exist := collected.Load(collectedKey{node: process.Node().Name(), name: name})
+	return exist
+}
+
+func factoryCollector() gen.ProcessBehavior {
+	return &collector{}
+}
+
+func (c *collector) Init(args ...any) error {
+	if len(args) == 0 {
+		return fmt.Errorf("radar collector: missing registry")
+	}
+	registry, ok := args[0].(*prometheus.Registry)
+	if ok == false {
+		return fmt.Errorf("radar collector: args[0] is not *prometheus.Registry")
+	}
+	c.registrar = prometheus.WrapRegistererWith(prometheus.Labels{"node": string(c.Node().Name())}, registry)
+	c.metrics = make(map[string]*collectorMetric)
+	c.owners = make(map[gen.PID][]string)
+	return nil
+}
+
+func (c *collector) HandleMessage(from gen.PID, message any) error {
+	switch m := message.(type) {
+	case messageObserve:
+		metric, exist := c.metrics[m.Name]
+		if exist == false {
+			c.Log().Warning("observe: unknown metric %q", m.Name)
+			return nil
+		}
+		observer, err := metric.observe(m.Labels)
+		if err != nil {
+			c.Log().Warning("observe %q: %s", m.Name, err)
+			return nil
+		}
+		observer.Observe(m.Value)
+
+	case gen.MessageDownPID:
+		for _, name := range c.owners[m.PID] {
+			c.unregister(name)
+		}
+		delete(c.owners, m.PID)
+
+	default:
+		c.Log().Warning("unknown message from %s: %#v", from, message)
+	}
+	return nil
+}
+
+func (c *collector) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	switch r := request.(type) {
+	case requestRegisterSummary:
+		objectives := r.Options.Objectives
+		if objectives == nil {
+			objectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+		}
+		vec := prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Name:       r.Name,
+			Help:       r.Help,
+			Objectives: objectives,
+			MaxAge:     r.Options.MaxAge,
+			AgeBuckets: r.Options.AgeBuckets,
+		}, r.Labels)
+		observe := func(labels []string) (prometheus.Observer, error) {
+			return vec.GetMetricWithLabelValues(labels...)
+		}
+		return c.register(from, r.Name, vec, observe), nil
+
+	case requestRegisterHistogram:
+		// client_golang keeps no classic buckets for the native histogram without
+		// buckets, and doesn't limit the number of native buckets (memory) by default
+		buckets := r.Buckets
+		if buckets == nil {
+			buckets = prometheus.DefBuckets
+		}
+		maxBuckets := r.Options.NativeMaxBucketNumber
+		if maxBuckets == 0 {
+			maxBuckets = defaultNativeMaxBucketNumber
+		}
+		minReset := r.Options.NativeMinResetDuration
+		if minReset == 0 {
+			minReset = defaultNativeMinResetDuration
+		}
+		vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:                            r.Name,
+			Help:                            r.Help,
+			Buckets:                         buckets,
+			NativeHistogramBucketFactor:     r.Options.NativeBucketFactor,
+			NativeHistogramZeroThreshold:    r.Options.NativeZeroThreshold,
+			NativeHistogramMaxBucketNumber:  maxBuckets,
+			NativeHistogramMinResetDuration: minReset,
+		}, r.Labels)
+		observe := func(labels []string) (prometheus.Observer, error) {
+			return vec.GetMetricWithLabelValues(labels...)
+		}
+		return c.register(from, r.Name, vec, observe), nil
+
+	case requestUnregister:
+		metric, exist := c.metrics[r.Name]
+		if exist == false {
+			return fmt.Errorf("unknown metric %q", r.Name), nil
+		}
+		c.unregister(r.Name)
+		names := c.owners[metric.owner]
+		for i, name := range names {
+			if name == r.Name {
+				c.owners[metric.owner] = append(names[:i], names[i+1:]...)
+				break
+			}
+		}
+		return nil, nil
+	}
+	return nil, gen.ErrUnsupported
+}
+
+func (c *collector) Terminate(reason error) {
+	for name := range c.metrics {
+		c.unregister(name)
+	}
+}
+
+// register returns error or nil (as the result of the Call)
+func (c *collector) register(owner gen.PID, name string, metric prometheus.Collector,
+	observe func(labels []string) (prometheus.Observer, error)) error {
+
+	if _, exist := c.metrics[name]; exist {
+		return fmt.Errorf("metric %q is already registered", name)
+	}
+	if err := c.registrar.Register(metric); err != nil {
+		return err
+	}
+	if _, exist := c.owners[owner]; exist == false {
+		// unregister the metrics of the process when it terminates
+		if err := c.MonitorPID(owner); err != nil {
+			c.registrar.Unregister(metric)
+			return err
+		}
+	}
+	c.metrics[name] = &collectorMetric{owner: owner, collector: metric, observe: observe}
+	c.owners[owner] = append(c.owners[owner], name)
+	collected.Store(collectedKey{node: c.Node().Name(), name: name}, struct{}{})
+	c.Log().Debug("registered metric %q (owner %s)", name, owner)
+	return nil
+}
+
+func (c *collector) unregister(name string) {
+	metric, exist := c.metrics[name]
+	if exist == false {
+		return
+	}
+	c.registrar.Unregister(metric.collector)
+	delete(c.metrics, name)
+	collected.Delete(collectedKey{node: c.Node().Name(), name: name})
+}
@@ -17,4 +17,5 @@ const (
 	nameMetrics     gen.Atom = "radar_metrics"
 	nameWeb         gen.Atom = "radar_web"
 	nameTopNSup     gen.Atom = "radar_topn_sup"
+	nameCollector   gen.Atom = "radar_collector"
 )
@@ -0,0 +1,79 @@
+package radar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// acceptProtobuf requests the metrics of actor/metrics in the delimited protobuf
+// format, so nothing is lost on decoding
+const acceptProtobuf = "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited"
+
+// metricsHandler serves the metrics of actor/metrics (registered on the internal mux)
+// merged with the radar registry (summaries and native histograms). The format is
+// negotiated with the scraper: text, OpenMetrics or protobuf. Native histograms
+// are exposed with protobuf only.
+func metricsHandler(internal http.Handler, path string, registry *prometheus.Registry) http.Handler {
+	ergoMetrics := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return gatherHandler(internal, path)
+	})
+	return promhttp.HandlerFor(prometheus.Gatherers{ergoMetrics, registry}, promhttp.HandlerOpts{
+		ErrorHandling:     promhttp.ContinueOnError,
+		EnableOpenMetrics: true,
+	})
+}
+
+// gatherHandler decodes the metrics served by the handler at the path
+func gatherHandler(handler http.Handler, path string) ([]*dto.MetricFamily, error) {
+	request, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", acceptProtobuf)
+
+	response := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+	handler.ServeHTTP(response, request)
+	if response.status != http.StatusOK {
+		return nil, fmt.Errorf("metrics handler returned %d: %s", response.status, response.body.String())
+	}
+
+	var families []*dto.MetricFamily
+	decoder := expfmt.NewDecoder(&response.body, expfmt.ResponseFormat(response.header))
+	for {
+		family := &dto.MetricFamily{}
+		if err := decoder.Decode(family); err != nil {
+			if errors.Is(err, io.EOF) {
+				return families, nil
+			}
+			return families, err
+		}
+		families = append(families, family)
+	}
+}
+
+// bufferedResponse keeps the response of the internal handler
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+func (r *bufferedResponse) WriteHeader(status int) {
+	r.status = status
+}
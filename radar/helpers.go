@@ -48,15 +48,50 @@ func RegisterCounter(process gen.Process, name, help string, labels []string) er
 }
 
 // RegisterHistogram registers a histogram metric via the metrics pool.
-func RegisterHistogram(process gen.Process, name, help string, labels []string, buckets []float64) error {
-	return metrics.RegisterHistogram(process, nameMetrics, name, help, labels, buckets)
+// With HistogramOptions enabling the native histogram it is registered
+// with the radar collector instead.
+func RegisterHistogram(process gen.Process, name, help string, labels []string, buckets []float64, options ...HistogramOptions) error {
+	if len(options) == 0 || options[0].NativeBucketFactor <= 1 {
+		return metrics.RegisterHistogram(process, nameMetrics, name, help, labels, buckets)
+	}
+	return callCollector(process, requestRegisterHistogram{
+		Name:    name,
+		Help:    help,
+		Labels:  labels,
+		Buckets: buckets,
+		Options: options[0],
+	})
+}
+
+// RegisterSummary registers a summary metric with the radar collector.
+func RegisterSummary(process gen.Process, name, help string, labels []string, options SummaryOptions) error {
+	return callCollector(process, requestRegisterSummary{
+		Name:    name,
+		Help:    help,
+		Labels:  labels,
+		Options: options,
+	})
 }
 
 // UnregisterMetric removes a previously registered custom metric.
 func UnregisterMetric(process gen.Process, name string) error {
+	if isCollected(process, name) {
+		return callCollector(process, requestUnregister{Name: name})
+	}
 	return metrics.Unregister(process, nameMetrics, name)
 }
 
+func callCollector(process gen.Process, request any) error {
+	result, err := process.Call(nameCollector, request)
+	if err != nil {
+		return err
+	}
+	if err, ok := result.(error); ok {
+		return err
+	}
+	return nil
+}
+
 // GaugeSet sets the value of a registered gauge metric.
 func GaugeSet(process gen.Process, name string, value float64, labels []string) error {
 	return metrics.GaugeSet(process, nameMetrics, name, value, labels)
@@ -74,9 +109,21 @@ func CounterAdd(process gen.Process, name string, value float64, labels []string
 
 // HistogramObserve observes a value on a registered histogram metric.
 func HistogramObserve(process gen.Process, name string, value float64, labels []string) error {
+	if isCollected(process, name) {
+		return observeCollected(process, name, value, labels)
+	}
 	return metrics.HistogramObserve(process, nameMetrics, name, value, labels)
 }
 
+// SummaryObserve observes a value on a registered summary metric.
+func SummaryObserve(process gen.Process, name string, value float64, labels []string) error {
+	return observeCollected(process, name, value, labels)
+}
+
+func observeCollected(process gen.Process, name string, value float64, labels []string) error {
+	return process.Send(nameCollector, messageObserve{Name: name, Value: value, Labels: labels})
+}
+
 // TopN helpers -- delegate to topN supervisor named "radar_topn_sup".
 
 // RegisterTopN registers a top-N metric. A dedicated actor is spawned to manage it.
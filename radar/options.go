@@ -1,6 +1,11 @@
 package radar
 
-import "time"
+import (
+	"crypto/x509"
+	"time"
+
+	"ergo.services/ergo/gen"
+)
 
 // Options configures the Radar application.
 type Options struct {
@@ -9,6 +14,15 @@ type Options struct {
 	// Port for the shared HTTP server. Default: 9090.
 	Port uint16
 
+	// CertManager enables TLS on the HTTP server. nil = plain HTTP.
+	CertManager gen.CertManager
+	// ClientCAs enables mutual TLS: the metrics endpoint accepts only clients presenting
+	// a certificate signed by one of these CAs. The health endpoints don't require it
+	// unless Auth.Health is set. Requires CertManager.
+	ClientCAs *x509.CertPool
+	// Auth configures Basic or Bearer authentication of the metrics endpoint. Empty = no auth.
+	Auth AuthOptions
+
 	// HealthPath is the URL prefix for health endpoints. Default: "/health".
 	HealthPath string
 	// MetricsPath is the URL path for the Prometheus metrics endpoint. Default: "/metrics".
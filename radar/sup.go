@@ -8,6 +8,7 @@ import (
 	"ergo.services/actor/metrics"
 	"ergo.services/ergo/act"
 	"ergo.services/ergo/gen"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type radarSup struct {
@@ -37,6 +38,24 @@ func (s *radarSup) Init(args ...any) (act.SupervisorSpec, error) {
 		return act.SupervisorSpec{}, fmt.Errorf("radar: 'shared' is not *metrics.Shared")
 	}
 
+	v, exist = s.Env("registry")
+	if exist == false {
+		return act.SupervisorSpec{}, fmt.Errorf("radar: missing 'registry' in env")
+	}
+	registry, ok := v.(*prometheus.Registry)
+	if ok == false {
+		return act.SupervisorSpec{}, fmt.Errorf("radar: 'registry' is not *prometheus.Registry")
+	}
+
+	v, exist = s.Env("metrics_mux")
+	if exist == false {
+		return act.SupervisorSpec{}, fmt.Errorf("radar: missing 'metrics_mux' in env")
+	}
+	metricsMux, ok := v.(*http.ServeMux)
+	if ok == false {
+		return act.SupervisorSpec{}, fmt.Errorf("radar: 'metrics_mux' is not *http.ServeMux")
+	}
+
 	v, exist = s.Env("options")
 	if exist == false {
 		return act.SupervisorSpec{}, fmt.Errorf("radar: missing 'options' in env")
@@ -53,7 +72,7 @@ func (s *radarSup) Init(args ...any) (act.SupervisorSpec, error) {
 	}
 
 	primaryMetricsOpts := metrics.Options{
-		Mux:             mux,
+		Mux:             metricsMux,
 		Shared:          shared,
 		Path:            options.MetricsPath,
 		CollectInterval: options.MetricsCollectInterval,
@@ -91,6 +110,11 @@ func (s *radarSup) Init(args ...any) (act.SupervisorSpec, error) {
 				Factory: factoryMetricsPool,
 				Args:    []any{poolOpts},
 			},
+			{
+				Name:    nameCollector,
+				Factory: factoryCollector,
+				Args:    []any{registry},
+			},
 			{
 				Name:    nameTopNSup,
 				Factory: factoryTopNSup,
@@ -99,7 +123,7 @@ func (s *radarSup) Init(args ...any) (act.SupervisorSpec, error) {
 			{
 				Name:    nameWeb,
 				Factory: factoryWeb,
-				Args:    []any{mux, options.Host, options.Port},
+				Args:    []any{mux, options},
 			},
 		},
 	}
@@ -0,0 +1,76 @@
+package radar
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"ergo.services/ergo/gen"
+)
+
+// createMTLSServer creates a meta process serving HTTPS with client certificate
+// verification. meta.WebServer has no option to request client certificates,
+// so the listener is created here with the TLS config built from CertManager.
+// The certificate is verified if given, and required by the handler (see authHandler),
+// so the health endpoints can stay available to the probes without certificates.
+func createMTLSServer(host string, port uint16, cm gen.CertManager, clientCAs *x509.CertPool, handler http.Handler) (gen.MetaBehavior, error) {
+	config := &tls.Config{
+		GetCertificate: cm.GetCertificateFunc(),
+		ClientCAs:      clientCAs,
+		ClientAuth:     tls.VerifyClientCertIfGiven,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	listener, err := tls.Listen("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mtlsServer{
+		listener: listener,
+		server:   &http.Server{Handler: handler},
+	}, nil
+}
+
+type mtlsServer struct {
+	gen.MetaProcess
+	listener net.Listener
+	server   *http.Server
+}
+
+func (s *mtlsServer) Init(process gen.MetaProcess) error {
+	s.MetaProcess = process
+	return nil
+}
+
+func (s *mtlsServer) Start() error {
+	err := s.server.Serve(s.listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *mtlsServer) HandleMessage(from gen.PID, message any) error {
+	return nil
+}
+
+func (s *mtlsServer) HandleCall(from gen.PID, ref gen.Ref, request any) (any, error) {
+	return gen.ErrUnsupported, nil
+}
+
+func (s *mtlsServer) Terminate(reason error) {
+	s.server.Close()
+	s.listener.Close()
+}
+
+func (s *mtlsServer) HandleInspect(from gen.PID, item ...string) map[string]string {
+	return map[string]string{
+		"listener":    s.listener.Addr().String(),
+		"client auth": "verify if given",
+	}
+}
@@ -1,6 +1,8 @@
 package radar
 
 import (
+	"time"
+
 	"ergo.services/actor/health"
 	"ergo.services/actor/metrics"
 )
@@ -21,3 +23,33 @@ const (
 	TopNMax = metrics.TopNMax
 	TopNMin = metrics.TopNMin
 )
+
+// SummaryOptions configures a summary registered with RegisterSummary.
+type SummaryOptions struct {
+	// Objectives maps the quantiles to their absolute errors.
+	// Default: {0.5: 0.05, 0.9: 0.01, 0.99: 0.001}.
+	Objectives map[float64]float64
+	// MaxAge is the duration the observations stay relevant for the quantiles. Default: 10m.
+	MaxAge time.Duration
+	// AgeBuckets is the number of buckets the MaxAge window is split into. Default: 5.
+	AgeBuckets uint32
+}
+
+// HistogramOptions enables Prometheus native (sparse) histograms in RegisterHistogram.
+// Native histograms are exposed in the protobuf format only, so Prometheus must
+// be started with --enable-feature=native-histograms. The classic buckets are
+// exposed along with them, the Prometheus default buckets if none are given.
+type HistogramOptions struct {
+	// NativeBucketFactor is the max ratio between the bounds of two consecutive
+	// buckets, e.g. 1.1. Must be greater than 1 to enable the native histogram.
+	NativeBucketFactor float64
+	// NativeZeroThreshold is the width of the zero bucket. Default: 2^-128.
+	NativeZeroThreshold float64
+	// NativeMaxBucketNumber limits the number of buckets (and the memory). When exceeded,
+	// the resolution is reduced or the histogram is reset (see NativeMinResetDuration).
+	// Default: 160.
+	NativeMaxBucketNumber uint32
+	// NativeMinResetDuration is the minimal time between the resets of the histogram.
+	// Default: 1h.
+	NativeMinResetDuration time.Duration
+}
@@ -18,33 +18,42 @@ func factoryWeb() gen.ProcessBehavior {
 }
 
 func (w *webActor) Init(args ...any) error {
-	if len(args) < 3 {
-		return fmt.Errorf("radar web: expected 3 args (mux, host, port), got %d", len(args))
+	if len(args) < 2 {
+		return fmt.Errorf("radar web: expected 2 args (mux, options), got %d", len(args))
 	}
 
 	mux, ok := args[0].(*http.ServeMux)
 	if ok == false {
 		return fmt.Errorf("radar web: args[0] is not *http.ServeMux")
 	}
-	host, ok := args[1].(string)
+	options, ok := args[1].(Options)
 	if ok == false {
-		return fmt.Errorf("radar web: args[1] is not string")
-	}
-	port, ok := args[2].(uint16)
-	if ok == false {
-		return fmt.Errorf("radar web: args[2] is not uint16")
+		return fmt.Errorf("radar web: args[1] is not radar.Options")
 	}
 
-	serverOptions := meta.WebServerOptions{
-		Port:    port,
-		Host:    host,
-		Handler: mux,
+	handler := authHandler(options, mux)
+
+	var webserver gen.MetaBehavior
+	var err error
+	if options.ClientCAs != nil {
+		if options.CertManager == nil {
+			return fmt.Errorf("radar web: ClientCAs requires CertManager")
+		}
+		webserver, err = createMTLSServer(options.Host, options.Port, options.CertManager, options.ClientCAs, handler)
+	} else {
+		serverOptions := meta.WebServerOptions{
+			Port:        options.Port,
+			Host:        options.Host,
+			Handler:     handler,
+			CertManager: options.CertManager,
+		}
+		webserver, err = meta.CreateWebServer(serverOptions)
 	}
-	webserver, err := meta.CreateWebServer(serverOptions)
 	if err != nil {
 		return err
 	}
 	if _, err := w.SpawnMeta(webserver, gen.MetaOptions{}); err != nil {
+		webserver.Terminate(err)
 		return err
 	}
 	return nil